@@ -4,7 +4,7 @@ import (
 	"k8s.io/klog"
 	"math/rand"
 	"math"
-	"github.com/randysimpson/go-matrix/matrix"
+	"github.com/randysimpson/ml-tutorial-go/ml"
 )
 
 func main() {
@@ -32,70 +32,42 @@ func main() {
 	klog.Infof("X: =%v\n", X)
 	klog.Infof("T: =%v\n", T)
 
-	//add 1's to our X slice, this is required so that we can apply matrix functions.
-	//and place them into slice of slieces.
+	//place X and T into slice of slices, adding 1's to X so that we can apply matrix functions.
 	var X1 [][]float64
-	for i := 0; i < sampleSize; i++ {
-		var arrayFloat []float64
-		arrayFloat = append(arrayFloat, 1.0)
-		arrayFloat = append(arrayFloat, X[i])
-		X1 = append(X1, arrayFloat)
-	}
-
-	//create slice of slieces for T's
 	var T1 [][]float64
 	for i := 0; i < sampleSize; i++ {
-		var arrayFloat []float64
-		arrayFloat = append(arrayFloat, T[i])
-		T1 = append(T1, arrayFloat)
+		X1 = append(X1, []float64{X[i]})
+		T1 = append(T1, []float64{T[i]})
 	}
+	X1 = ml.AddBias(X1)
 
 	learning_rate := 0.01
 	epoch := 10
 
 	//setup weight matrix as initially all zeros.
-	var w [][]float64
-	for i := 0; i < len(X1[0]); i++ { //length of row in X
-		var arrayFloat []float64
-		for j := 0; j < len(T1[0]); j++ { //length of row in T
-			arrayFloat = append(arrayFloat, 0.0)
-		}
-		w = append(w, arrayFloat)
-	}
+	w := ml.Zeros(len(X1[0]), len(T1[0]))
 	
 	klog.Infof("learning_rate: =%v\n", learning_rate)
 	klog.Infof("epoch: =%v\n", epoch)
 	klog.Infof("Initial w: =%v\n", w)
 
-	for i := 0; i < epoch; i++ {
-		for j := 0; j < sampleSize; j++ {
-			//get the x values as a slice of slices, currently it's just a single slice.
-			var xMatrix [][]float64
-			xMatrix = append(xMatrix, X1[j])
-
-			//multiply it by weight matrix to get predicted value for y using model
-			y := matrix.Multiply(xMatrix, w)
-			
-			//put the target values into slice of slices, currently this is single slice as well.
-			var tMatrix [][]float64
-			tMatrix = append(tMatrix, T1[j])
-
-			//find the error
-			err := matrix.Subtract(tMatrix, y)
-
-			//Find the amount of change to apply to the weight matrix
-			xT := matrix.Transpose(xMatrix)
-			diff := matrix.Multiply(xT, err)
-			change := matrix.MultiplyScalar(diff, learning_rate)
-
-			//add the change to the weight matrix
-			w = matrix.Add(w, change)
-		}
+	//visit the samples in the same order every epoch so the output matches the walk through.
+	sgd := ml.SGD{LearningRate: learning_rate, Epochs: epoch, NoShuffle: true}
+	w, err := sgd.Train(X1, T1, w)
+	if err != nil {
+		klog.Fatalf("Error training: %v\n", err)
 	}
 
 	klog.Infof("Final w = %v\n", w)
 
+	//for a data set this small we can also solve for w directly, which gives a reference for the SGD result.
+	exactW, err := ml.LeastSquares(X1, T1)
+	if err != nil {
+		klog.Fatalf("Error solving for w: %v\n", err)
+	}
+	klog.Infof("Least squares w = %v\n", exactW)
+
 	//run the model against the X values
-	predicted := matrix.Multiply(X1, w)
+	predicted := ml.Predict(X1, w)
 	klog.Infof("predicted y's= %v\n", predicted)
-}
\ No newline at end of file
+}
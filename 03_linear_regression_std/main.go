@@ -2,118 +2,13 @@ package main
 
 import (
 	"k8s.io/klog"
-	"math"
-	"math/rand"
-	"github.com/randysimpson/go-matrix/matrix"
-	"bufio"
-	"os"
-	"strings"
-	"strconv"
+	"github.com/randysimpson/ml-tutorial-go/ml"
 )
 
-func ReadCSV(filename string, beginColumn int, endColumn int) ([][]float64, error) {
-	var result [][]float64
-
-	file, err := os.Open(filename)
-	if err != nil {
-		return result, err
-	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		//split the line into columns based on the comma
-		columns := strings.Split(scanner.Text(), ",")
-		
-		var data []float64
-
-		//only get columns desired.
-		for _, v := range columns[beginColumn:endColumn + 1] {
-			//convert from string to float64
-			f, err := strconv.ParseFloat(v, 64)
-			if err != nil {
-				return result, err
-			}
-
-			data = append(data,f)
-		}
-		result = append(result, data)
-	}
-
-	if err := scanner.Err(); err != nil {
-		return result, err
-	}
-
-	return result, nil
-}
-
-func UniqueRandomSlice(low int, high int, count int) []int {
-	var result []int
-
-	for i := 0; i < count; i++ {
-		//ensure we get unique number.
-		isUnique := false
-		val := -1
-		for !isUnique {
-			isUnique = true
-			val = rand.Intn(high - low) + low
-			for _, v := range result {
-				if v == val {
-					isUnique = false
-					break
-				}
-			}
-		}
-		result = append(result, val)
-	}
-
-	return result
-}
-
-func MeanByColumn(slice [][]float64) []float64 {
-	rowCount := len(slice)
-	colLengh := len(slice[0])
-	var sums []float64
-	//initialize to zeros
-	for c := 0; c < colLengh; c++ {
-		sums = append(sums, 0.0)
-	}
-	for r := 0; r < rowCount; r++ {
-		for c := 0; c < colLengh; c++ {
-			sums[c] += slice[r][c]
-		}
-	}
-	for c := 0; c < colLengh; c++ {
-		sums[c] = sums[c] / float64(rowCount)
-	}
-	return sums
-}
-
-func StdDevByColumn(slice [][]float64) []float64 {
-	rowCount := len(slice)
-	colLengh := len(slice[0])
-	var result []float64
-	//find mean by column
-	means := MeanByColumn(slice)
-	//initialize to zeros
-	for c := 0; c < colLengh; c++ {
-		result = append(result, 0.0)
-	}
-	for r := 0; r < rowCount; r++ {
-		for c := 0; c < colLengh; c++ {
-			result[c] += math.Pow(slice[r][c] - means[c], 2.0)
-		}
-	}
-	for c := 0; c < colLengh; c++ {
-		result[c] = math.Sqrt(result[c] / float64(rowCount))
-	}
-	return result
-}
-
 func main() {
 	klog.Infoln("Initializing ml tutorial application");
 
-	data, err :=ReadCSV("winequality-red.csv", 0, 11)
+	data, err := ml.ReadCSV("winequality-red.csv", 0, 11)
 	if err != nil {
 		klog.Errorf("Error loading file: %v\n", err)
 	}
@@ -121,99 +16,48 @@ func main() {
 
 	sampleSize := len(data)
 	//build the X and T slice of slices from the data
-	var X [][]float64
-	var T [][]float64
-	//get the length of the data slice for 1 row
-	dataSliceLength := len(data[0])
-	for i := 0; i < sampleSize; i++ {
-		//setup X matrix
-		var sliceX []float64
-		//we will add 1 as the first item
-		sliceX = append(sliceX, 1.0)
-		//we skip the last index in the data as that will be used in target matrix
-		for j := 0; j < dataSliceLength - 1; j++ {
-			sliceX = append(sliceX, data[i][j])
-		}
-		//add to X matrix
-		X = append(X, sliceX)
-
-		//setup T matrix
-		var sliceT []float64
-		sliceT = append(sliceT, data[i][11])
-		//add to T matrix
-		T = append(T, sliceT)
-	}
+	//we will add 1 as the first item of X and skip the last index as that will be used in target matrix
+	X := ml.AddBias(ml.SelectColumns(data, ml.ColumnRange(0, 10)))
+	T := ml.SelectColumns(data, []int{11})
 
 	klog.Infof("X: =%v\n", X)
 	klog.Infof("T: =%v\n", T)
 
-	trainCount := int(math.Round(float64(sampleSize) * 0.8))
-	testCount := sampleSize - trainCount
-	trainIndexs := UniqueRandomSlice(0, sampleSize, trainCount)
+	//split the rows up with a fixed seed so the run can be reproduced.
+	seed := int64(1)
+	split, err := ml.Splitter{Train: 0.8, Seed: seed}.Split(sampleSize)
+	if err != nil {
+		klog.Fatalf("Error splitting data: %v\n", err)
+	}
 
-	klog.Infof("Training count: =%v\n", trainCount)
-	klog.Infof("Testing count: =%v\n", testCount)
-	klog.Infof("Train indexes: =%v\n", trainIndexs)
+	klog.Infof("Seed: =%v\n", seed)
+	klog.Infof("Training count: =%v\n", len(split.Train))
+	klog.Infof("Testing count: =%v\n", len(split.Test))
+	klog.Infof("Train indexes: =%v\n", split.Train)
 
 	//create matrices for the training and test data.
-	var Xtrain [][]float64
-	var Ttrain [][]float64
-	var Xtest [][]float64
-	var Ttest [][]float64
-	for i := 0; i < sampleSize; i++ {
-		isTrainIndex := false
-		for _, v := range trainIndexs {
-			if v == i {
-				isTrainIndex = true
-				break
-			}
-		}
-		if isTrainIndex {
-			Xtrain = append(Xtrain, X[i])
-			Ttrain = append(Ttrain, T[i])
-		} else {
-			Xtest = append(Xtest, X[i])
-			Ttest = append(Ttest, T[i])
-		}
-	}
+	Xtrain, Ttrain := ml.Rows(X, split.Train), ml.Rows(T, split.Train)
+	Xtest, Ttest := ml.Rows(X, split.Test), ml.Rows(T, split.Test)
 
 	klog.Infof("Xtrain: =%v\n", Xtrain)
 	klog.Infof("Ttrain: =%v\n", Ttrain)
 	klog.Infof("Xtest: =%v\n", Xtest)
 	klog.Infof("Ttest: =%v\n", Ttest)
 
-	//get standardized info about the training data only
-	xMeans := MeanByColumn(Xtrain)
-	klog.Infof("xMeans= %v\n", xMeans)
-	xStds := StdDevByColumn(Xtrain)
-	klog.Infof("xStds= %v\n", xStds)
+	//get standardized info about the training data only, the 1st column is left as it is.
+	scaler := &ml.StandardScaler{Skip: []int{0}}
+	if err := scaler.Fit(Xtrain); err != nil {
+		klog.Fatalf("Error fitting scaler: %v\n", err)
+	}
+	klog.Infof("xMeans= %v\n", scaler.Means)
+	klog.Infof("xStds= %v\n", scaler.Stds)
 
 	//standardize data, except for the 1st column...
-	var XStdTrain [][]float64
-	for r := 0; r < len(Xtrain); r++ {
-		var rowData []float64
-		//1st column is still 1
-		rowData = append(rowData, 1.0)
-		for c := 1; c < len(Xtrain[0]); c++ {
-			val := (Xtrain[r][c] - xMeans[c]) / xStds[c]
-			rowData = append(rowData, val)
-		}
-		XStdTrain = append(XStdTrain, rowData)
-	}
+	XStdTrain := scaler.Transform(Xtrain)
 	klog.Infof("XStdTrain= %v\n", XStdTrain)
 
 	//standardize the test data.
-	var XStdTest [][]float64
-	for r := 0; r < len(Xtest); r++ {
-		var rowData []float64
-		//1st column is still 1
-		rowData = append(rowData, 1.0)
-		for c := 1; c < len(Xtest[0]); c++ {
-			val := (Xtest[r][c] - xMeans[c]) / xStds[c]
-			rowData = append(rowData, val)
-		}
-		XStdTest = append(XStdTest, rowData)
-	}
+	XStdTest := scaler.Transform(Xtest)
 	klog.Infof("XStdTest= %v\n", XStdTest)
 
 
@@ -221,68 +65,81 @@ func main() {
 	epoch := 5
 
 	//setup weight matrix as initially all zeros.
-	var w [][]float64
-	for i := 0; i < len(XStdTrain[0]); i++ { //length of row in X
-		var sliceFloat []float64
-		for j := 0; j < len(Ttrain[0]); j++ { //length of row in T
-			sliceFloat = append(sliceFloat, 0.0)
-		}
-		w = append(w, sliceFloat)
-	}
+	w := ml.Zeros(len(XStdTrain[0]), len(Ttrain[0]))
 	
 	klog.Infof("learning_rate: =%v\n", learning_rate)
 	klog.Infof("epoch: =%v\n", epoch)
 	klog.Infof("Initial w: =%v\n", w)
 
-	rmse := 0.0
-	for i := 0; i < epoch; i++ {
-		sqerrorSum := 0.0
-
-		for j := 0; j < trainCount; j++ {
-			//get the x values as a slice of slices, currently it's just a single slice.
-			var xMatrix [][]float64
-			xMatrix = append(xMatrix, XStdTrain[j])
-
-			//multiply it by weight matrix to get predicted value for y using model
-			y := matrix.Multiply(xMatrix, w)
-			
-			//put the target values into slice of slices, currently this is single slice as well.
-			var tMatrix [][]float64
-			tMatrix = append(tMatrix, Ttrain[j])
-
-			//find the error
-			err := matrix.Subtract(tMatrix, y)
-
-			//Find the amount of change to apply to the weight matrix
-			xT := matrix.Transpose(xMatrix)
-			diff := matrix.Multiply(xT, err)
-			change := matrix.MultiplyScalar(diff, learning_rate)
-
-			//add the change to the weight matrix
-			w = matrix.Add(w, change)
-
-			//add to sqerrorSum
-			sqerror := matrix.Multiply(err, err) //same as saying err^2
-			sqerrorSum += sqerror[0][0] //it becomes a matrix of size 1 x 1
-
-			rmse = math.Sqrt(sqerrorSum / float64(trainCount))
-		}
-		klog.Infof("RMSE = %v\n", rmse)
+	//visit the samples in the same order every epoch so the output matches the walk through.
+	sgd := ml.SGD{
+		LearningRate: learning_rate,
+		Epochs: epoch,
+		NoShuffle: true,
+		OnEpoch: func(i int, rmse []float64) {
+			klog.Infof("RMSE = %v\n", rmse[0])
+		},
+	}
+	w, err = sgd.Train(XStdTrain, Ttrain, w)
+	if err != nil {
+		klog.Fatalf("Error training: %v\n", err)
 	}
 
 	klog.Infof("Final w = %v\n", w)
 
 	//run the model against the Xtest values
-	predicted := matrix.Multiply(XStdTest, w)
+	predicted := ml.Predict(XStdTest, w)
 	klog.Infof("predicted y's= %v\n", predicted)
 
-	testError := matrix.Subtract(predicted, Ttest)
-	square := matrix.Multiply(testError, testError)
-	sumError := 0.0
-	for _, v := range square {
-		sumError += v[0]
+	mse := ml.MSE(predicted, Ttest)
+	klog.Infof("find sqrt of = %v\n", mse[0])
+	rmse := ml.RMSE(predicted, Ttest)
+	klog.Infof("rmse= %v\n", rmse[0])
+
+	//a single split can be lucky or unlucky, so compare raw and standardized inputs with 5-fold cross-validation
+	folds, err := ml.CrossValidation{K: 5, Seed: seed}.KFold(sampleSize)
+	if err != nil {
+		klog.Fatalf("Error building folds: %v\n", err)
 	}
-	klog.Infof("find sqrt of = %v\n", sumError / float64(len(square)))
-	rmse = math.Sqrt(sumError / float64(len(square)))
-	klog.Infof("rmse= %v\n", rmse)
-}
\ No newline at end of file
+	raw, err := ml.CrossValidate(func() ml.Model {
+		return &ml.SGDRegression{SGD: ml.SGD{LearningRate: 0.0000001, Epochs: 20}}
+	}, X, T, folds)
+	if err != nil {
+		klog.Fatalf("Error cross-validating: %v\n", err)
+	}
+	klog.Infof("raw cross-validation rmse= %v +/- %v\n", raw.MeanRMSE[0], raw.StdRMSE[0])
+	std, err := ml.CrossValidate(func() ml.Model {
+		return &ml.Scaled{
+			Model: &ml.SGDRegression{SGD: ml.SGD{LearningRate: learning_rate, Epochs: epoch}},
+			Scaler: &ml.StandardScaler{Skip: []int{0}},
+		}
+	}, X, T, folds)
+	if err != nil {
+		klog.Fatalf("Error cross-validating: %v\n", err)
+	}
+	klog.Infof("standardized cross-validation rmse= %v +/- %v\n", std.MeanRMSE[0], std.StdRMSE[0])
+
+	//compare the other scalers on the test data, each one is fit on the training data only and leaves the 1st column as it is.
+	//box-cox is left out as it needs positive values and citric acid has 0's, yeo-johnson accepts them.
+	scalers := []struct {
+		name string
+		scaler ml.Scaler
+	}{
+		{"standard", &ml.StandardScaler{Skip: []int{0}}},
+		{"min-max", &ml.MinMaxScaler{Skip: []int{0}}},
+		{"robust", &ml.RobustScaler{Skip: []int{0}}},
+		{"max-abs", &ml.MaxAbsScaler{Skip: []int{0}}},
+		{"log1p then standard", &ml.ChainScaler{Scalers: []ml.Scaler{&ml.Log1pScaler{Skip: []int{0}}, &ml.StandardScaler{Skip: []int{0}}}}},
+		{"yeo-johnson then standard", &ml.ChainScaler{Scalers: []ml.Scaler{&ml.YeoJohnsonScaler{Skip: []int{0}}, &ml.StandardScaler{Skip: []int{0}}}}},
+	}
+	for _, s := range scalers {
+		model := &ml.Scaled{
+			Model: &ml.SGDRegression{SGD: ml.SGD{LearningRate: learning_rate, Epochs: epoch, NoShuffle: true}},
+			Scaler: s.scaler,
+		}
+		if err := model.Fit(Xtrain, Ttrain); err != nil {
+			klog.Fatalf("Error training with the %v scaler: %v\n", s.name, err)
+		}
+		klog.Infof("%v test rmse= %v\n", s.name, ml.RMSE(model.Predict(Xtest), Ttest)[0])
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"k8s.io/klog"
+	"math/rand"
+	"github.com/randysimpson/go-matrix/matrix"
+	"github.com/randysimpson/ml-tutorial-go/ml"
+)
+
+//randomMatrix returns a rows x cols matrix of random values.
+func randomMatrix(rng *rand.Rand, rows int, cols int) [][]float64 {
+	result := ml.Zeros(rows, cols)
+	for r := range result {
+		for c := range result[r] {
+			result[r][c] = rng.Float64()
+		}
+	}
+	return result
+}
+
+func main() {
+	klog.Infoln("Initializing ml tutorial application");
+
+	rng := rand.New(rand.NewSource(1))
+	//the first size is the shape of predicting the wine data, the others are square matrices.
+	sizes := [][]int{{1599, 12, 2}, {256, 256, 256}, {512, 512, 512}}
+
+	for _, size := range sizes {
+		A := randomMatrix(rng, size[0], size[1])
+		B := randomMatrix(rng, size[1], size[2])
+		klog.Infof("A is %v x %v, B is %v x %v\n", size[0], size[1], size[1], size[2])
+
+		expected := matrix.Multiply(A, B)
+		d := ml.NewDense(size[0], size[2], nil)
+		ml.Multiplier{}.Mul(d, ml.DenseOf(A), ml.DenseOf(B))
+
+		//the blocked multiply adds the values up in the same order, so the results match exactly.
+		same := true
+		for r := range expected {
+			for c := range expected[r] {
+				if expected[r][c] != d.At(r, c) {
+					same = false
+				}
+			}
+		}
+		klog.Infof("same result: =%v\n", same)
+	}
+
+	//the timings are measured by the benchmarks of the ml package, see the README.
+}
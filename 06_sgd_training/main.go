@@ -0,0 +1,163 @@
+package main
+
+import (
+	"k8s.io/klog"
+	"github.com/randysimpson/ml-tutorial-go/ml"
+)
+
+func main() {
+	klog.Infoln("Initializing ml tutorial application");
+
+	//the wine data is shared with module 02, and the csv file has no header so name the columns ourselves.
+	dataset, err := ml.LoadCSV("../02_linear_regression_applied/winequality-red.csv", ml.CSVOptions{
+		Header: ml.HeaderAbsent,
+		Names: []string{"fixed acidity", "volatile acidity", "citric acid", "residual sugar",
+			"chlorides", "free sulfur dioxide", "total sulfur dioxide", "density", "pH",
+			"sulphates", "alcohol", "quality"},
+	})
+	if err != nil {
+		klog.Fatalf("Error loading file: %v\n", err)
+	}
+
+	features, targets, err := dataset.Targets("quality")
+	if err != nil {
+		klog.Fatalf("Error selecting targets: %v\n", err)
+	}
+	X := ml.AddBias(features.Data)
+	T := targets.Data
+
+	//split the rows into training, validation and test sets with a fixed seed so the run can be reproduced.
+	seed := int64(1)
+	split, err := ml.Splitter{Train: 0.6, Validation: 0.2, Seed: seed}.Split(len(X))
+	if err != nil {
+		klog.Fatalf("Error splitting data: %v\n", err)
+	}
+	klog.Infof("Seed: =%v\n", seed)
+	klog.Infof("Training count: =%v\n", len(split.Train))
+	klog.Infof("Validation count: =%v\n", len(split.Validation))
+	klog.Infof("Testing count: =%v\n", len(split.Test))
+
+	Xtrain, Ttrain := ml.Rows(X, split.Train), ml.Rows(T, split.Train)
+	Xvalidation, Tvalidation := ml.Rows(X, split.Validation), ml.Rows(T, split.Validation)
+	Xtest, Ttest := ml.Rows(X, split.Test), ml.Rows(T, split.Test)
+
+	//standardize everything with the training data only, the 1st column is left as it is.
+	scaler := &ml.StandardScaler{Skip: []int{0}}
+	XStdTrain, err := ml.FitTransform(scaler, Xtrain)
+	if err != nil {
+		klog.Fatalf("Error fitting scaler: %v\n", err)
+	}
+	XStdValidation := scaler.Transform(Xvalidation)
+	XStdTest := scaler.Transform(Xtest)
+
+	learning_rate := 0.01
+	epoch := 30
+
+	klog.Infof("learning_rate: =%v\n", learning_rate)
+	klog.Infof("epoch: =%v\n", epoch)
+
+	//every schedule starts from the same learning rate and changes it from epoch to epoch.
+	schedules := []struct {
+		name     string
+		schedule ml.Schedule
+	}{
+		{"constant", nil},
+		{"step decay", ml.StepDecay{Step: 10, Factor: 0.5}},
+		{"exponential decay", ml.ExponentialDecay{Decay: 0.9}},
+		{"inverse time decay", ml.InverseTimeDecay{Decay: 0.5}},
+		{"cosine annealing with warm restarts", ml.CosineAnnealing{Period: 5, Multiplier: 2}},
+		{"linear warmup then cosine annealing", ml.LinearWarmup{Epochs: 5, Schedule: ml.CosineAnnealing{Period: 25}}},
+		{"reduce on plateau", &ml.ReduceOnPlateau{Factor: 0.5, Patience: 2}},
+	}
+
+	for _, s := range schedules {
+		klog.Infof("Schedule: %v\n", s.name)
+		sgd := ml.SGD{
+			LearningRate: learning_rate,
+			Epochs: epoch,
+			Seed: seed,
+			Schedule: s.schedule,
+			ValidationX: XStdValidation,
+			ValidationT: Tvalidation,
+			OnReport: func(r ml.EpochReport) {
+				klog.Infof("epoch %v: learning rate = %v, RMSE = %v, validation RMSE = %v\n", r.Epoch, r.Rate, r.TrainRMSE[0], r.ValidationRMSE[0])
+			},
+		}
+		w, err := sgd.Train(XStdTrain, Ttrain, ml.Zeros(len(XStdTrain[0]), len(Ttrain[0])))
+		if err != nil {
+			klog.Fatalf("Error training: %v\n", err)
+		}
+
+		rmse := ml.RMSE(ml.Predict(XStdTest, w), Ttest)
+		klog.Infof("%v test rmse= %v\n", s.name, rmse[0])
+	}
+
+	//instead of a fixed number of epochs, hold out a quarter of the training rows and stop once their error has not improved for 5 epochs.
+	stopping := &ml.EarlyStopping{Fraction: 0.25, Patience: 5}
+	sgd := ml.SGD{
+		LearningRate: learning_rate,
+		Epochs: 200,
+		Seed: seed,
+		EarlyStopping: stopping,
+		OnReport: func(r ml.EpochReport) {
+			klog.Infof("epoch %v: RMSE = %v, validation RMSE = %v\n", r.Epoch, r.TrainRMSE[0], r.ValidationRMSE[0])
+		},
+	}
+	w, err := sgd.Train(XStdTrain, Ttrain, ml.Zeros(len(XStdTrain[0]), len(Ttrain[0])))
+	if err != nil {
+		klog.Fatalf("Error training: %v\n", err)
+	}
+	klog.Infof("Stopped after epoch %v, best weights from epoch %v with validation RMSE %v\n", stopping.StoppedEpoch, stopping.BestEpoch, stopping.BestScore)
+
+	rmse := ml.RMSE(ml.Predict(XStdTest, w), Ttest)
+	klog.Infof("early stopping test rmse= %v\n", rmse[0])
+
+	//a learning rate that is far too large makes the weights blow up, training stops with an error instead of carrying on with NaN.
+	sgd = ml.SGD{
+		LearningRate: 1,
+		Epochs: epoch,
+		Seed: seed,
+	}
+	_, err = sgd.Train(XStdTrain, Ttrain, ml.Zeros(len(XStdTrain[0]), len(Ttrain[0])))
+	if divergence, ok := err.(*ml.DivergenceError); ok {
+		klog.Infof("learning_rate 1 diverged at epoch %v, step %v: %v\n", divergence.Epoch, divergence.Step, divergence.Reason)
+	} else if err != nil {
+		klog.Fatalf("Error training: %v\n", err)
+	}
+
+	//stop as soon as the loss of an epoch changes by less than the tolerance.
+	convergence := &ml.Convergence{Tolerance: 1e-3}
+	sgd = ml.SGD{
+		LearningRate: learning_rate,
+		Epochs: 200,
+		Seed: seed,
+		Convergence: convergence,
+	}
+	w, err = sgd.Train(XStdTrain, Ttrain, ml.Zeros(len(XStdTrain[0]), len(Ttrain[0])))
+	if err != nil {
+		klog.Fatalf("Error training: %v\n", err)
+	}
+	klog.Infof("Converged at epoch %v with loss %v\n", convergence.Epoch, convergence.Loss)
+
+	rmse = ml.RMSE(ml.Predict(XStdTest, w), Ttest)
+	klog.Infof("convergence test rmse= %v\n", rmse[0])
+
+	//rather than guessing the learning rate, sweep it from 1e-8 up to 10 over an epoch and watch where the loss falls fastest.
+	finder := &ml.RateFinder{}
+	sgd = ml.SGD{
+		Epochs: epoch,
+		Seed: seed,
+		RateFinder: finder,
+	}
+	w, err = sgd.Train(XStdTrain, Ttrain, ml.Zeros(len(XStdTrain[0]), len(Ttrain[0])))
+	if err != nil {
+		klog.Fatalf("Error training: %v\n", err)
+	}
+	for i := 0; i < len(finder.Rates); i += 50 {
+		klog.Infof("rate %v: loss = %v\n", finder.Rates[i], finder.Losses[i])
+	}
+	klog.Infof("Suggested learning_rate: =%v\n", finder.Rate)
+
+	rmse = ml.RMSE(ml.Predict(XStdTest, w), Ttest)
+	klog.Infof("rate finder test rmse= %v\n", rmse[0])
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"k8s.io/klog"
+	"math"
+	"github.com/randysimpson/ml-tutorial-go/ml"
+)
+
+//norm returns the length of the weights as one long vector.
+func norm(w [][]float64) float64 {
+	sum := 0.0
+	for _, row := range w {
+		for _, v := range row {
+			sum += v * v
+		}
+	}
+	return math.Sqrt(sum)
+}
+
+//names returns the names of the features at the indexes of w, which are one past their column because of the bias.
+func names(features []string, indexes []int) []string {
+	var result []string
+	for _, j := range indexes {
+		result = append(result, features[j-1])
+	}
+	return result
+}
+
+func main() {
+	klog.Infoln("Initializing ml tutorial application");
+
+	//the wine data is shared with module 02, and the csv file has no header so name the columns ourselves.
+	dataset, err := ml.LoadCSV("../02_linear_regression_applied/winequality-red.csv", ml.CSVOptions{
+		Header: ml.HeaderAbsent,
+		Names: []string{"fixed acidity", "volatile acidity", "citric acid", "residual sugar",
+			"chlorides", "free sulfur dioxide", "total sulfur dioxide", "density", "pH",
+			"sulphates", "alcohol", "quality"},
+	})
+	if err != nil {
+		klog.Fatalf("Error loading file: %v\n", err)
+	}
+
+	features, targets, err := dataset.Targets("quality")
+	if err != nil {
+		klog.Fatalf("Error selecting targets: %v\n", err)
+	}
+	X := ml.AddBias(features.Data)
+	T := targets.Data
+
+	//split the rows into training, validation and test sets with a fixed seed so the run can be reproduced.
+	seed := int64(1)
+	split, err := ml.Splitter{Train: 0.6, Validation: 0.2, Seed: seed}.Split(len(X))
+	if err != nil {
+		klog.Fatalf("Error splitting data: %v\n", err)
+	}
+	klog.Infof("Seed: =%v\n", seed)
+	klog.Infof("Training count: =%v\n", len(split.Train))
+	klog.Infof("Validation count: =%v\n", len(split.Validation))
+	klog.Infof("Testing count: =%v\n", len(split.Test))
+
+	Xtrain, Ttrain := ml.Rows(X, split.Train), ml.Rows(T, split.Train)
+	Xvalidation, Tvalidation := ml.Rows(X, split.Validation), ml.Rows(T, split.Validation)
+	Xtest, Ttest := ml.Rows(X, split.Test), ml.Rows(T, split.Test)
+
+	//standardize everything with the training data only so that every weight is penalized on the same scale, the 1st column is left as it is.
+	scaler := &ml.StandardScaler{Skip: []int{0}}
+	XStdTrain, err := ml.FitTransform(scaler, Xtrain)
+	if err != nil {
+		klog.Fatalf("Error fitting scaler: %v\n", err)
+	}
+	XStdValidation := scaler.Transform(Xvalidation)
+	XStdTest := scaler.Transform(Xtest)
+
+	//the weights without any regularization to compare against.
+	exactW, err := ml.LeastSquares(XStdTrain, Ttrain)
+	if err != nil {
+		klog.Fatalf("Error solving least squares: %v\n", err)
+	}
+	klog.Infof("least squares w: =%v\n", exactW)
+
+	//train a ridge model for every alpha from 0.0001 to 10 and measure it on the validation set.
+	alphas := ml.LogSpace(0.0001, 10, 11)
+	path, err := ml.RegularizationPath(func(alpha float64) ml.Model {
+		return &ml.RidgeRegression{Alpha: alpha}
+	}, alphas, XStdTrain, Ttrain, XStdValidation, Tvalidation)
+	if err != nil {
+		klog.Fatalf("Error training path: %v\n", err)
+	}
+	for _, p := range path {
+		w := p.Model.(*ml.RidgeRegression).W
+		klog.Infof("alpha %.4g: RMSE = %v, validation RMSE = %v, |w| = %v\n", p.Alpha, p.TrainRMSE[0], p.ValidationRMSE[0], norm(w[1:]))
+	}
+
+	best := ml.BestPoint(path)
+	bestW := best.Model.(*ml.RidgeRegression).W
+	klog.Infof("Best alpha: =%.4g\n", best.Alpha)
+	klog.Infof("ridge w: =%v\n", bestW)
+
+	rmse := ml.RMSE(ml.Predict(XStdTest, exactW), Ttest)
+	klog.Infof("least squares test rmse= %v\n", rmse[0])
+	rmse = ml.RMSE(ml.Predict(XStdTest, bestW), Ttest)
+	klog.Infof("ridge test rmse= %v\n", rmse[0])
+
+	//SGD gets the same penalty from L2, with full batches and enough epochs it ends up at the same weights.
+	learning_rate := 0.3
+	epoch := 2000
+	sgd := ml.SGD{
+		LearningRate: learning_rate,
+		Epochs: epoch,
+		BatchSize: ml.FullBatch,
+		L2: best.Alpha,
+	}
+	w, err := sgd.Train(XStdTrain, Ttrain, ml.Zeros(len(XStdTrain[0]), len(Ttrain[0])))
+	if err != nil {
+		klog.Fatalf("Error training: %v\n", err)
+	}
+	klog.Infof("sgd w: =%v\n", w)
+
+	rmse = ml.RMSE(ml.Predict(XStdTest, w), Ttest)
+	klog.Infof("sgd ridge test rmse= %v\n", rmse[0])
+
+	//the lasso drops features altogether, start from the smallest alpha that drops every feature and work down, each fit starting from the weights of the one before.
+	lasso := ml.Lasso(0)
+	maxAlpha := lasso.MaxAlpha(XStdTrain, Ttrain)
+	path, err = lasso.Path(ml.LogSpace(maxAlpha/1000, maxAlpha, 10), XStdTrain, Ttrain, XStdValidation, Tvalidation)
+	if err != nil {
+		klog.Fatalf("Error training path: %v\n", err)
+	}
+	for _, p := range path {
+		model := p.Model.(*ml.ElasticNet)
+		klog.Infof("alpha %.4g: passes = %v, validation RMSE = %v, features = %v\n", p.Alpha, model.Iterations, p.ValidationRMSE[0], names(features.Names, model.NonZero()))
+	}
+	best = ml.BestPoint(path)
+	bestLasso := best.Model.(*ml.ElasticNet)
+	klog.Infof("Best alpha: =%.4g\n", best.Alpha)
+	klog.Infof("lasso w: =%v\n", bestLasso.W)
+	rmse = ml.RMSE(bestLasso.Predict(XStdTest), Ttest)
+	klog.Infof("lasso test rmse= %v\n", rmse[0])
+
+	//the elastic net mixes the two penalties, which keeps groups of correlated features together, and visits the weights in a random order.
+	elasticNet := ml.ElasticNet{L1Ratio: 0.5, Random: true, Seed: seed}
+	maxAlpha = elasticNet.MaxAlpha(XStdTrain, Ttrain)
+	path, err = elasticNet.Path(ml.LogSpace(maxAlpha/1000, maxAlpha, 10), XStdTrain, Ttrain, XStdValidation, Tvalidation)
+	if err != nil {
+		klog.Fatalf("Error training path: %v\n", err)
+	}
+	best = ml.BestPoint(path)
+	bestElasticNet := best.Model.(*ml.ElasticNet)
+	klog.Infof("elastic net best alpha: =%.4g, features = %v\n", best.Alpha, names(features.Names, bestElasticNet.NonZero()))
+	rmse = ml.RMSE(bestElasticNet.Predict(XStdTest), Ttest)
+	klog.Infof("elastic net test rmse= %v\n", rmse[0])
+
+	//predict alcohol and quality together like module 4, the multi-task lasso keeps the same features for both.
+	multiFeatures, multiTargets, err := dataset.Targets("alcohol", "quality")
+	if err != nil {
+		klog.Fatalf("Error selecting targets: %v\n", err)
+	}
+	XMulti := ml.AddBias(multiFeatures.Data)
+	TMulti := multiTargets.Data
+	multiScaler := &ml.StandardScaler{Skip: []int{0}}
+	XMultiTrain, err := ml.FitTransform(multiScaler, ml.Rows(XMulti, split.Train))
+	if err != nil {
+		klog.Fatalf("Error fitting scaler: %v\n", err)
+	}
+	//the targets are standardized too so that both count the same in the penalty.
+	targetScaler := &ml.StandardScaler{}
+	TMultiTrain, err := ml.FitTransform(targetScaler, ml.Rows(TMulti, split.Train))
+	if err != nil {
+		klog.Fatalf("Error fitting scaler: %v\n", err)
+	}
+
+	alpha := 0.05
+	multiTask := ml.MultiTaskLasso(alpha)
+	if err := multiTask.Fit(XMultiTrain, TMultiTrain); err != nil {
+		klog.Fatalf("Error training: %v\n", err)
+	}
+	separate := ml.Lasso(alpha)
+	if err := separate.Fit(XMultiTrain, TMultiTrain); err != nil {
+		klog.Fatalf("Error training: %v\n", err)
+	}
+	klog.Infof("multi-task lasso features = %v\n", names(multiFeatures.Names, multiTask.NonZero()))
+	klog.Infof("multi-task lasso w: =%v\n", multiTask.W)
+	klog.Infof("separate lasso w: =%v\n", separate.W)
+
+	XMultiTest := multiScaler.Transform(ml.Rows(XMulti, split.Test))
+	TMultiTest := ml.Rows(TMulti, split.Test)
+	rmse = ml.RMSE(targetScaler.InverseTransform(multiTask.Predict(XMultiTest)), TMultiTest)
+	klog.Infof("multi-task lasso test rmse= %v\n", rmse)
+	rmse = ml.RMSE(targetScaler.InverseTransform(separate.Predict(XMultiTest)), TMultiTest)
+	klog.Infof("separate lasso test rmse= %v\n", rmse)
+}
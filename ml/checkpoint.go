@@ -0,0 +1,125 @@
+package ml
+
+import (
+	"encoding/json"
+	"io/ioutil"
+)
+
+// Checkpoint is a snapshot of SGD training after an epoch: the weights and
+// the state of the optimizer, of early stopping and of a ReduceOnPlateau
+// schedule.  It can be saved and training resumed from it with SGD.Resume.
+type Checkpoint struct {
+	//Epoch is the number of epochs that have been run.
+	Epoch int         `json:"epoch"`
+	W     [][]float64 `json:"w"`
+	//Optimizer is the optimizer with the state it had built up, nil for
+	//plain gradient descent.
+	Optimizer Optimizer `json:"-"`
+	//EarlyStopping is the state of early stopping, nil when it is not used.
+	EarlyStopping *EarlyStoppingState `json:"earlyStopping,omitempty"`
+	//Plateau is the state of a ReduceOnPlateau schedule, on its own or
+	//following a LinearWarmup, nil for any other schedule.
+	Plateau *PlateauState `json:"plateau,omitempty"`
+}
+
+// EarlyStoppingState is what EarlyStopping has learned so far in a training
+// run: the best weights, their epoch and error, and the number of epochs
+// since.
+type EarlyStoppingState struct {
+	BestEpoch int         `json:"bestEpoch"`
+	BestScore float64     `json:"bestScore"`
+	Wait      int         `json:"wait"`
+	W         [][]float64 `json:"w"`
+}
+
+// PlateauState is the Scale, Best, Wait and Cooling of a ReduceOnPlateau.
+type PlateauState struct {
+	Scale   float64 `json:"scale"`
+	Best    float64 `json:"best"`
+	Wait    int     `json:"wait"`
+	Cooling int     `json:"cooling"`
+}
+
+// savedCheckpoint is how a Checkpoint is written to JSON, with the type of
+// its optimizer.
+type savedCheckpoint struct {
+	*plainCheckpoint
+	Optimizer *savedOptimizer `json:"optimizer,omitempty"`
+}
+
+type plainCheckpoint Checkpoint
+
+// MarshalJSON saves the checkpoint with the type of its optimizer.
+func (c Checkpoint) MarshalJSON() ([]byte, error) {
+	saved := savedCheckpoint{plainCheckpoint: (*plainCheckpoint)(&c)}
+	if c.Optimizer != nil {
+		var err error
+		if saved.Optimizer, err = marshalOptimizer(c.Optimizer); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(saved)
+}
+
+// UnmarshalJSON reads a checkpoint saved by MarshalJSON.
+func (c *Checkpoint) UnmarshalJSON(data []byte) error {
+	saved := savedCheckpoint{plainCheckpoint: (*plainCheckpoint)(c)}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return err
+	}
+	c.Optimizer = nil
+	if saved.Optimizer != nil {
+		var err error
+		if c.Optimizer, err = unmarshalOptimizer(saved.Optimizer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Save writes the checkpoint to a JSON file.
+func (c *Checkpoint) Save(filename string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, data, 0644)
+}
+
+// LoadCheckpoint reads a checkpoint written by Save.
+func LoadCheckpoint(filename string) (*Checkpoint, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	result := &Checkpoint{}
+	if err := json.Unmarshal(data, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// snapshot returns a checkpoint holding copies of w, the optimizer and the
+// state of the schedule, so that it is not changed as training goes on.  An
+// optimizer of a type that can't be saved can't be copied either, so the
+// checkpoint shares it with the training run and its state keeps changing.
+func (s SGD) snapshot(epoch int, w *Dense) *Checkpoint {
+	result := &Checkpoint{Epoch: epoch, W: w.Slices(), Optimizer: s.Optimizer}
+	if copied, err := copyOptimizer(s.Optimizer); err == nil {
+		result.Optimizer = copied
+	}
+	if plateau := plateauOf(s.Schedule); plateau != nil {
+		result.Plateau = &PlateauState{Scale: plateau.Scale, Best: plateau.Best, Wait: plateau.Wait, Cooling: plateau.Cooling}
+	}
+	return result
+}
+
+// copyOptimizer returns a copy of an optimizer and its state, made by saving
+// it and reading it back.
+func copyOptimizer(optimizer Optimizer) (Optimizer, error) {
+	saved, err := marshalOptimizer(optimizer)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalOptimizer(saved)
+}
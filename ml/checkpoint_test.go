@@ -0,0 +1,81 @@
+package ml
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+)
+
+// noisyLine returns samples of line() with noise added to the targets.
+func noisyLine(seed int64) ([][]float64, [][]float64) {
+	rng := rand.New(rand.NewSource(seed))
+	X, T := line()
+	for i := range T {
+		T[i][0] += rng.NormFloat64()
+	}
+	return X, T
+}
+
+func TestResume(t *testing.T) {
+	X, T := noisyLine(1)
+	//validation targets of half the size make the validation error fall and
+	//then rise again as the weights grow
+	validationX, validationT := noisyLine(2)
+	for i := range validationT {
+		validationT[i][0] /= 2
+	}
+	newSGD := func() SGD {
+		return SGD{
+			LearningRate:  0.05,
+			Epochs:        40,
+			Seed:          1,
+			Optimizer:     &Momentum{},
+			Schedule:      LinearWarmup{Epochs: 2, Schedule: &ReduceOnPlateau{Patience: 2, Factor: 0.5}},
+			EarlyStopping: &EarlyStopping{Patience: 20},
+			ValidationX:   validationX,
+			ValidationT:   validationT,
+		}
+	}
+
+	//train without stopping, saving a checkpoint part way through
+	const at = 15
+	var saved []byte
+	whole := newSGD()
+	whole.OnCheckpoint = func(c *Checkpoint) {
+		if c.Epoch == at {
+			var err error
+			if saved, err = json.Marshal(c); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	want, err := whole.Train(X, T, Zeros(3, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var c Checkpoint
+	if err := json.Unmarshal(saved, &c); err != nil {
+		t.Fatal(err)
+	}
+	if c.EarlyStopping == nil || c.Plateau == nil {
+		t.Fatalf("checkpoint %s has no early stopping or plateau state", saved)
+	}
+	resumed := newSGD()
+	got, err := resumed.Resume(&c, X, T)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "w", got, want, 1e-12)
+	//the best epoch comes before the checkpoint, so the resumed run only stops
+	//in the same place when it remembers it
+	if resumed.EarlyStopping.BestEpoch >= at || resumed.EarlyStopping.BestEpoch != whole.EarlyStopping.BestEpoch ||
+		resumed.EarlyStopping.StoppedEpoch != whole.EarlyStopping.StoppedEpoch {
+		t.Fatalf("resumed run stopped at epoch %d with the best at %d, want %d and %d", resumed.EarlyStopping.StoppedEpoch,
+			resumed.EarlyStopping.BestEpoch, whole.EarlyStopping.StoppedEpoch, whole.EarlyStopping.BestEpoch)
+	}
+	wantPlateau := plateauOf(whole.Schedule)
+	if gotPlateau := plateauOf(resumed.Schedule); *gotPlateau != *wantPlateau {
+		t.Fatalf("resumed plateau %+v, want %+v", *gotPlateau, *wantPlateau)
+	}
+}
@@ -0,0 +1,136 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+)
+
+// DivergenceError is returned by SGD when training blows up: the loss or the
+// weights have become NaN or infinite, or the loss has grown far past where
+// it started.  This usually means the learning rate is too large.
+type DivergenceError struct {
+	Epoch int
+	//Step is the batch within the epoch, counting from 0.
+	Step int
+	//Loss is the mean squared error of the epoch up to and including the
+	//step.
+	Loss   float64
+	Reason string
+}
+
+func (e *DivergenceError) Error() string {
+	return fmt.Sprintf("sgd: diverged at epoch %d, step %d: %s (loss %v), try a smaller learning rate", e.Epoch, e.Step, e.Reason, e.Loss)
+}
+
+// DefaultMaxLossGrowth is used when SGD.MaxLossGrowth is not set.
+const DefaultMaxLossGrowth = 1e4
+
+// Convergence stops SGD once training has settled down, when the loss of an
+// epoch changes by less than Tolerance from the epoch before or the norm of
+// the average gradient over an epoch is less than GradientTolerance.  Either
+// can be left at zero to not use it.
+//
+// After training Epoch is the epoch training converged at, or -1 when it ran
+// every epoch.
+type Convergence struct {
+	Tolerance         float64
+	GradientTolerance float64
+
+	Epoch int
+	//Loss is the mean squared error and GradientNorm the norm of the average
+	//gradient of the last epoch that was run.
+	Loss         float64
+	GradientNorm float64
+}
+
+// monitor watches a training run for divergence and convergence.
+type monitor struct {
+	convergence   *Convergence
+	maxLossGrowth float64
+	//firstLoss is the loss of the first epoch that was run, lastLoss of the
+	//one before the current epoch.
+	firstLoss, lastLoss float64
+	started             bool
+	//gradientSum adds up the gradients of an epoch when their norm is needed.
+	gradientSum *Dense
+	steps       int
+}
+
+func (s SGD) newMonitor(w *Dense) *monitor {
+	result := &monitor{convergence: s.Convergence, maxLossGrowth: s.MaxLossGrowth}
+	if result.maxLossGrowth == 0 {
+		result.maxLossGrowth = DefaultMaxLossGrowth
+	}
+	if c := s.Convergence; c != nil {
+		c.Epoch, c.Loss, c.GradientNorm = -1, math.NaN(), math.NaN()
+		if c.GradientTolerance > 0 {
+			result.gradientSum = NewDense(w.rows, w.cols, nil)
+		}
+	}
+	return result
+}
+
+// step checks the loss after a step of an epoch, and adds gradient to the
+// sum for the epoch.  gradient may be nil when the caller adds to
+// gradientSum itself.
+func (m *monitor) step(epoch int, step int, seen int, sqerrorSum []float64, gradient *Dense) error {
+	if loss := mean(sqerrorSum); math.IsNaN(loss) || math.IsInf(loss, 0) {
+		return &DivergenceError{Epoch: epoch, Step: step, Loss: loss / float64(seen), Reason: "loss is not a number"}
+	}
+	if m.gradientSum != nil && gradient != nil {
+		m.gradientSum.Add(m.gradientSum, gradient)
+	}
+	m.steps++
+	return nil
+}
+
+// endEpoch checks the weights and the loss after an epoch of steps, and
+// returns true when training has converged.
+func (m *monitor) endEpoch(epoch int, samples int, sqerrorSum []float64, w *Dense) (bool, error) {
+	loss := mean(sqerrorSum) / float64(samples)
+	steps := m.steps
+	m.steps = 0
+	first := !m.started
+	m.started = true
+	for r := 0; r < w.rows; r++ {
+		for _, v := range w.Row(r) {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				return false, &DivergenceError{Epoch: epoch, Step: steps - 1, Loss: loss, Reason: "weights are not numbers"}
+			}
+		}
+	}
+	if first {
+		m.firstLoss = loss
+	} else if m.firstLoss > 0 && loss > m.maxLossGrowth*m.firstLoss {
+		return false, &DivergenceError{Epoch: epoch, Step: steps - 1, Loss: loss,
+			Reason: fmt.Sprintf("loss grew to more than %v times the loss of the first epoch", m.maxLossGrowth)}
+	}
+
+	c := m.convergence
+	if c == nil {
+		m.lastLoss = loss
+		return false, nil
+	}
+	c.Loss = loss
+	converged := false
+	if m.gradientSum != nil {
+		//the norm of the average gradient
+		sum := 0.0
+		for r := 0; r < w.rows; r++ {
+			for _, g := range m.gradientSum.Row(r) {
+				sum += g * g
+			}
+		}
+		c.GradientNorm = math.Sqrt(sum) / float64(steps)
+		m.gradientSum.Zero()
+		converged = c.GradientNorm < c.GradientTolerance
+	}
+	if c.Tolerance > 0 && !first && math.Abs(loss-m.lastLoss) < c.Tolerance {
+		converged = true
+	}
+	m.lastLoss = loss
+	if converged {
+		c.Epoch = epoch
+	}
+	return converged, nil
+}
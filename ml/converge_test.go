@@ -0,0 +1,92 @@
+package ml
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestDivergenceError(t *testing.T) {
+	tests := []struct {
+		name   string
+		sgd    SGD
+		X      [][]float64
+		T      [][]float64
+		epoch  int
+		step   int
+		reason string
+	}{
+		//the second sample alone squares to more than a float64 can hold
+		{"loss not a number", SGD{LearningRate: 0.1, Epochs: 10, NoShuffle: true}, [][]float64{{1}, {1e200}}, [][]float64{{1}, {1}}, 0, 1, "loss is not a number"},
+		//a rate of 3 turns the error w - 1 into -2 times itself every epoch, so the loss grows 4 times
+		//an epoch and passes 100 times the first at epoch 4
+		{"loss grew", SGD{LearningRate: 3, Epochs: 10, BatchSize: FullBatch, MaxLossGrowth: 100}, [][]float64{{1}}, [][]float64{{1}}, 4, 0, "loss grew to more than 100 times"},
+		{"huge rate", SGD{LearningRate: 1e6, Epochs: 100, Seed: 1}, nil, nil, -1, -1, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			X, T := test.X, test.T
+			if X == nil {
+				X, T = line()
+			}
+			w, err := test.sgd.Train(X, T, Zeros(len(X[0]), 1))
+			var divergence *DivergenceError
+			if !errors.As(err, &divergence) {
+				t.Fatalf("got error %v, want a *DivergenceError", err)
+			}
+			if test.epoch >= 0 && (divergence.Epoch != test.epoch || divergence.Step != test.step) {
+				t.Fatalf("diverged at epoch %d, step %d, want %d, %d", divergence.Epoch, divergence.Step, test.epoch, test.step)
+			}
+			assertErrorContains(t, err, test.reason)
+			assertErrorContains(t, err, "try a smaller learning rate")
+			if len(w) != len(X[0]) {
+				t.Fatalf("got %d rows of weights, want %d", len(w), len(X[0]))
+			}
+
+			//the sparse version stops at the same place
+			sparseErr := test.sgd.TrainCSR(CSROf(X), DenseOf(T), DenseOf(Zeros(len(X[0]), 1)))
+			var sparse *DivergenceError
+			if !errors.As(sparseErr, &sparse) || sparse.Epoch != divergence.Epoch || sparse.Step != divergence.Step || sparse.Reason != divergence.Reason {
+				t.Fatalf("TrainCSR got error %v, want %v", sparseErr, err)
+			}
+		})
+	}
+}
+
+func TestConvergence(t *testing.T) {
+	X, T := line()
+	tests := []struct {
+		name        string
+		convergence *Convergence
+	}{
+		{"loss", &Convergence{Tolerance: 1e-12}},
+		{"gradient", &Convergence{GradientTolerance: 1e-6}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			epochs := 0
+			s := SGD{LearningRate: 0.05, Epochs: 1000, Seed: 1, Convergence: test.convergence, OnEpoch: func(int, []float64) { epochs++ }}
+			w, err := s.Train(X, T, Zeros(3, 1))
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := test.convergence
+			if c.Epoch < 0 || c.Epoch >= s.Epochs-1 || epochs != c.Epoch+1 {
+				t.Fatalf("converged at epoch %d after %d epochs, want to stop early", c.Epoch, epochs)
+			}
+			if c.Loss > 1e-8 || math.IsNaN(c.GradientNorm) != (c.GradientTolerance == 0) {
+				t.Fatalf("Loss = %v, GradientNorm = %v", c.Loss, c.GradientNorm)
+			}
+			assertClose(t, "w", w, [][]float64{{1}, {2}, {-3}}, 1e-4)
+		})
+	}
+
+	//a budget too small to settle in runs every epoch
+	c := &Convergence{Tolerance: 1e-12}
+	if _, err := (SGD{LearningRate: 0.05, Epochs: 3, Seed: 1, Convergence: c}).Train(X, T, Zeros(3, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if c.Epoch != -1 {
+		t.Fatalf("Epoch = %d, want -1 when every epoch was run", c.Epoch)
+	}
+}
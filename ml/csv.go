@@ -0,0 +1,237 @@
+package ml
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ParseError is returned when a CSV or LIBSVM file can not be read, it tells
+// where in the file the problem is.
+type ParseError struct {
+	Filename string
+	Line     int //line number in the file, starting at 1
+	Column   int //column (field) number in the line, starting at 1
+	Name     string
+	Err      error
+}
+
+func (e *ParseError) Error() string {
+	where := fmt.Sprintf("line %d, column %d", e.Line, e.Column)
+	if e.Filename != "" {
+		where = fmt.Sprintf("%s: %s", e.Filename, where)
+	}
+	if e.Name != "" {
+		where = fmt.Sprintf("%s (%q)", where, e.Name)
+	}
+	return fmt.Sprintf("%s: %v", where, e.Err)
+}
+
+// record is one line of a CSV file split into fields.
+type record struct {
+	line   int
+	fields []string
+}
+
+// csvReader splits RFC 4180 style text into records.  Quoted fields may hold
+// the delimiter, line breaks and doubled quotes, lines may end in \n or \r\n,
+// blank lines and comment lines are skipped and a leading byte order mark is
+// ignored.
+type csvReader struct {
+	r         *bufio.Reader
+	delimiter rune
+	quote     rune
+	comment   rune
+	line      int
+	column    int
+}
+
+func newCSVReader(r io.Reader, options CSVOptions) *csvReader {
+	result := &csvReader{
+		r:         bufio.NewReader(r),
+		delimiter: options.Delimiter,
+		quote:     options.Quote,
+		comment:   options.Comment,
+		line:      1,
+	}
+	if result.delimiter == 0 {
+		result.delimiter = ','
+	}
+	if result.quote == 0 {
+		result.quote = '"'
+	}
+	return result
+}
+
+func (c *csvReader) errorf(format string, args ...interface{}) error {
+	return &ParseError{Line: c.line, Column: c.column, Err: fmt.Errorf(format, args...)}
+}
+
+// readAll returns every record of the input.
+func (c *csvReader) readAll() ([]record, error) {
+	var result []record
+
+	//skip the byte order mark
+	if r, _, err := c.r.ReadRune(); err == nil && r != '\uFEFF' {
+		c.r.UnreadRune()
+	}
+
+	for {
+		rec, err := c.readRecord()
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return result, err
+		}
+		if rec.fields != nil {
+			result = append(result, rec)
+		}
+	}
+}
+
+// readRecord reads one line, returning a record with no fields for blank and
+// comment lines.
+func (c *csvReader) readRecord() (record, error) {
+	rec := record{line: c.line}
+	c.column = 1
+
+	r, _, err := c.r.ReadRune()
+	if err != nil {
+		return rec, err
+	}
+	if c.comment != 0 && r == c.comment {
+		return rec, c.skipLine()
+	}
+	c.r.UnreadRune()
+
+	var quoted bool
+	for {
+		field, end, err := c.readField()
+		if err != nil {
+			return rec, err
+		}
+		quoted = quoted || field.quoted
+		rec.fields = append(rec.fields, field.value)
+		if end {
+			break
+		}
+		c.column++
+	}
+
+	//a line without any text is blank
+	if len(rec.fields) == 1 && !quoted && strings.TrimSpace(rec.fields[0]) == "" {
+		rec.fields = nil
+	}
+	return rec, nil
+}
+
+type csvField struct {
+	value  string
+	quoted bool
+}
+
+// readField reads one field and the delimiter or line break after it, end is
+// true when the field was the last one on its line.
+func (c *csvReader) readField() (field csvField, end bool, err error) {
+	var b strings.Builder
+
+	r, _, err := c.r.ReadRune()
+	if err == io.EOF {
+		return field, true, nil
+	}
+	if err != nil {
+		return field, true, err
+	}
+
+	if r == c.quote {
+		field.quoted = true
+		start := c.line
+		for {
+			r, _, err = c.r.ReadRune()
+			if err == io.EOF {
+				return field, true, &ParseError{Line: start, Column: c.column, Err: fmt.Errorf("quoted field is not closed")}
+			}
+			if err != nil {
+				return field, true, err
+			}
+			if r == c.quote {
+				//a doubled quote is a quote character inside the field
+				next, _, err := c.r.ReadRune()
+				if err == nil && next == c.quote {
+					b.WriteRune(c.quote)
+					continue
+				}
+				if err == nil {
+					c.r.UnreadRune()
+				}
+				break
+			}
+			if r == '\n' {
+				c.line++
+			}
+			b.WriteRune(r)
+		}
+		field.value = b.String()
+
+		//after the closing quote only a delimiter or the end of the line may follow
+		r, _, err = c.r.ReadRune()
+		if err == io.EOF {
+			return field, true, nil
+		}
+		if err != nil {
+			return field, true, err
+		}
+		if r == '\r' {
+			r, _, err = c.r.ReadRune()
+			if err == io.EOF {
+				return field, true, nil
+			}
+		}
+		switch r {
+		case c.delimiter:
+			return field, false, nil
+		case '\n':
+			c.line++
+			return field, true, nil
+		}
+		return field, true, c.errorf("unexpected %q after quoted field", r)
+	}
+
+	for {
+		switch r {
+		case c.delimiter:
+			field.value = b.String()
+			return field, false, nil
+		case '\n':
+			c.line++
+			field.value = strings.TrimSuffix(b.String(), "\r")
+			return field, true, nil
+		}
+		b.WriteRune(r)
+
+		r, _, err = c.r.ReadRune()
+		if err == io.EOF {
+			field.value = strings.TrimSuffix(b.String(), "\r")
+			return field, true, nil
+		}
+		if err != nil {
+			return field, true, err
+		}
+	}
+}
+
+// skipLine reads up to and including the next line break.
+func (c *csvReader) skipLine() error {
+	for {
+		r, _, err := c.r.ReadRune()
+		if err != nil {
+			return err
+		}
+		if r == '\n' {
+			c.line++
+			return nil
+		}
+	}
+}
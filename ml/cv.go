@@ -0,0 +1,238 @@
+package ml
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// Model is a model that can be trained on inputs X and targets T and then used
+// to make predictions.
+type Model interface {
+	Fit(X [][]float64, T [][]float64) error
+	Predict(X [][]float64) [][]float64
+}
+
+// SGDRegression is a linear model trained with SGD starting from zero
+// weights.  It implements Model.
+type SGDRegression struct {
+	SGD SGD
+	W   [][]float64
+}
+
+// Fit trains the weights on X and T.
+func (m *SGDRegression) Fit(X [][]float64, T [][]float64) error {
+	var err error
+	m.W, err = m.SGD.Train(X, T, Zeros(len(X[0]), len(T[0])))
+	return err
+}
+
+// Predict runs the trained weights against every row of X.
+func (m *SGDRegression) Predict(X [][]float64) [][]float64 {
+	return Predict(X, m.W)
+}
+
+// Scaled wraps a model so that its inputs, its targets or both are rescaled
+// with scalers fit on the data the model is fit on.  Predictions are converted
+// back to the original units of the targets, so errors measured on them stay
+// in those units.
+type Scaled struct {
+	Model Model
+	//Scaler, when set, rescales the inputs.
+	Scaler Scaler
+	//TargetScaler, when set, rescales the targets.
+	TargetScaler Scaler
+}
+
+// Fit rescales X and T and fits the wrapped model.
+func (m *Scaled) Fit(X [][]float64, T [][]float64) error {
+	var err error
+	if m.Scaler != nil {
+		if X, err = FitTransform(m.Scaler, X); err != nil {
+			return err
+		}
+	}
+	if m.TargetScaler != nil {
+		if T, err = FitTransform(m.TargetScaler, T); err != nil {
+			return err
+		}
+	}
+	return m.Model.Fit(X, T)
+}
+
+// Predict rescales X, runs the wrapped model and converts the predictions
+// back to the units of the targets.
+func (m *Scaled) Predict(X [][]float64) [][]float64 {
+	if m.Scaler != nil {
+		X = m.Scaler.Transform(X)
+	}
+	predicted := m.Model.Predict(X)
+	if m.TargetScaler != nil {
+		predicted = m.TargetScaler.InverseTransform(predicted)
+	}
+	return predicted
+}
+
+// Fold holds the row indexes used for training and for testing in one round
+// of cross-validation.
+type Fold struct {
+	Train []int
+	Test  []int
+}
+
+// CrossValidation builds the folds for k-fold cross-validation.  The same
+// Seed always gives the same folds.
+type CrossValidation struct {
+	//K is the number of folds.
+	K int
+	//Repeats is how many times k-fold is run with a different shuffle, it
+	//defaults to 1.
+	Repeats int
+
+	Seed int64
+	//Rand, when set, is used instead of a source created from Seed.
+	Rand *rand.Rand
+}
+
+// KFold shuffles the rows 0 through n-1 and divides them into K folds of
+// nearly equal size.  Every row is tested exactly once per repeat.
+func (cv CrossValidation) KFold(n int) ([]Fold, error) {
+	if cv.K < 2 || cv.K > n {
+		return nil, fmt.Errorf("cross-validation: can't make %d folds of %d rows", cv.K, n)
+	}
+	rng := cv.rand()
+	var result []Fold
+	for repeat := 0; repeat < cv.repeats(); repeat++ {
+		assigned := make([]int, n)
+		for i, r := range rng.Perm(n) {
+			assigned[r] = i % cv.K
+		}
+		result = append(result, foldsOf(assigned, cv.K)...)
+	}
+	return result, nil
+}
+
+// StratifiedKFold divides the rows into K folds so that every class of y is
+// spread evenly over the folds.  When bins is more than zero y is continuous
+// and is first divided into that many quantile bins.  Rows with a missing
+// label, NaN, are spread as a class of their own.
+func (cv CrossValidation) StratifiedKFold(y []float64, bins int) ([]Fold, error) {
+	if cv.K < 2 || cv.K > len(y) {
+		return nil, fmt.Errorf("cross-validation: can't make %d folds of %d rows", cv.K, len(y))
+	}
+
+	classes := strata(y, bins)
+	rng := cv.rand()
+	var result []Fold
+	for repeat := 0; repeat < cv.repeats(); repeat++ {
+		assigned := make([]int, len(y))
+		//deal the rows of each class out in turn, carrying on from where the
+		//last class stopped so the folds stay the same size
+		next := 0
+		for _, rows := range classes {
+			for _, p := range rng.Perm(len(rows)) {
+				assigned[rows[p]] = next % cv.K
+				next++
+			}
+		}
+		result = append(result, foldsOf(assigned, cv.K)...)
+	}
+	return result, nil
+}
+
+// LeaveOneOut returns n folds that each test a single row.
+func LeaveOneOut(n int) []Fold {
+	result := make([]Fold, n)
+	for i := range result {
+		var train []int
+		for r := 0; r < n; r++ {
+			if r != i {
+				train = append(train, r)
+			}
+		}
+		result[i] = Fold{Train: train, Test: []int{i}}
+	}
+	return result
+}
+
+func (cv CrossValidation) repeats() int {
+	if cv.Repeats < 1 {
+		return 1
+	}
+	return cv.Repeats
+}
+
+func (cv CrossValidation) rand() *rand.Rand {
+	if cv.Rand != nil {
+		return cv.Rand
+	}
+	return rand.New(rand.NewSource(cv.Seed))
+}
+
+// foldsOf turns the fold number of every row into k folds.
+func foldsOf(assigned []int, k int) []Fold {
+	result := make([]Fold, k)
+	for r, f := range assigned {
+		for i := range result {
+			if i == f {
+				result[i].Test = append(result[i].Test, r)
+			} else {
+				result[i].Train = append(result[i].Train, r)
+			}
+		}
+	}
+	return result
+}
+
+// FoldScore holds the errors of one fold, with a value for every output
+// column.
+type FoldScore struct {
+	TrainRMSE []float64
+	TestRMSE  []float64
+	TestMAE   []float64
+}
+
+// CVResult holds the errors of every fold and their mean and standard
+// deviation over the folds.
+type CVResult struct {
+	Folds []FoldScore
+
+	MeanRMSE []float64
+	StdRMSE  []float64
+	MeanMAE  []float64
+	StdMAE   []float64
+}
+
+// CrossValidate trains a new model from newModel on the training rows of every
+// fold and scores it on the test rows.
+func CrossValidate(newModel func() Model, X [][]float64, T [][]float64, folds []Fold) (*CVResult, error) {
+	if len(folds) == 0 {
+		return nil, fmt.Errorf("cross-validation: no folds")
+	}
+
+	result := &CVResult{}
+	var rmses, maes [][]float64
+	for i, fold := range folds {
+		Xtrain, Ttrain := Rows(X, fold.Train), Rows(T, fold.Train)
+		Xtest, Ttest := Rows(X, fold.Test), Rows(T, fold.Test)
+
+		model := newModel()
+		if err := model.Fit(Xtrain, Ttrain); err != nil {
+			return nil, fmt.Errorf("cross-validation: fold %d: %v", i, err)
+		}
+		predicted := model.Predict(Xtest)
+		score := FoldScore{
+			TrainRMSE: RMSE(model.Predict(Xtrain), Ttrain),
+			TestRMSE:  RMSE(predicted, Ttest),
+			TestMAE:   MAE(predicted, Ttest),
+		}
+		result.Folds = append(result.Folds, score)
+		rmses = append(rmses, score.TestRMSE)
+		maes = append(maes, score.TestMAE)
+	}
+
+	result.MeanRMSE = MeanByColumn(rmses)
+	result.StdRMSE = StdDevByColumn(rmses)
+	result.MeanMAE = MeanByColumn(maes)
+	result.StdMAE = StdDevByColumn(maes)
+	return result, nil
+}
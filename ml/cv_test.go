@@ -0,0 +1,220 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestStratifiedKFoldMissing(t *testing.T) {
+	nan := math.NaN()
+	y := []float64{nan, 1, nan, 1, nan, 1, nan, 1}
+	folds, err := CrossValidation{K: 2, Seed: 1}.StratifiedKFold(y, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, fold := range folds {
+		if len(fold.Train)+len(fold.Test) != len(y) {
+			t.Fatalf("fold %d %v lost rows", i, fold)
+		}
+		if countMissing(y, fold.Test) != 2 {
+			t.Fatalf("fold %d %v doesn't test half of the missing labels", i, fold)
+		}
+	}
+}
+
+// checkFolds checks that every repeat of k folds tests each of the n rows
+// exactly once, trains on all of the others and has fold sizes that differ by
+// at most 1.
+func checkFolds(t *testing.T, folds []Fold, n int, k int) {
+	t.Helper()
+	if len(folds)%k != 0 {
+		t.Fatalf("got %d folds, want a multiple of %d", len(folds), k)
+	}
+	for start := 0; start < len(folds); start += k {
+		tested := make([]int, n)
+		smallest, largest := n, 0
+		for i, fold := range folds[start : start+k] {
+			if len(fold.Train)+len(fold.Test) != n {
+				t.Fatalf("fold %d has %d training and %d test rows, want %d in all", start+i, len(fold.Train), len(fold.Test), n)
+			}
+			inTest := make(map[int]bool)
+			for _, r := range fold.Test {
+				tested[r]++
+				inTest[r] = true
+			}
+			for _, r := range fold.Train {
+				if inTest[r] {
+					t.Fatalf("fold %d trains and tests on row %d", start+i, r)
+				}
+			}
+			if len(fold.Test) < smallest {
+				smallest = len(fold.Test)
+			}
+			if len(fold.Test) > largest {
+				largest = len(fold.Test)
+			}
+		}
+		for r, count := range tested {
+			if count != 1 {
+				t.Fatalf("row %d is tested %d times in the repeat starting at fold %d", r, count, start)
+			}
+		}
+		if largest-smallest > 1 {
+			t.Fatalf("fold sizes range from %d to %d", smallest, largest)
+		}
+	}
+}
+
+func TestKFold(t *testing.T) {
+	tests := []struct {
+		name string
+		cv   CrossValidation
+		n    int
+	}{
+		{"even", CrossValidation{K: 5, Seed: 1}, 20},
+		{"uneven", CrossValidation{K: 5, Seed: 1}, 23},
+		{"one row a fold", CrossValidation{K: 7, Seed: 1}, 7},
+		{"repeats", CrossValidation{K: 3, Repeats: 4, Seed: 1}, 10},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			folds, err := test.cv.KFold(test.n)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(folds) != test.cv.K*test.cv.repeats() {
+				t.Fatalf("got %d folds, want %d", len(folds), test.cv.K*test.cv.repeats())
+			}
+			checkFolds(t, folds, test.n, test.cv.K)
+		})
+	}
+
+	_, err := CrossValidation{K: 1}.KFold(10)
+	assertErrorContains(t, err, "can't make 1 folds of 10 rows")
+	_, err = CrossValidation{K: 11}.KFold(10)
+	assertErrorContains(t, err, "can't make 11 folds of 10 rows")
+}
+
+func TestKFoldSeed(t *testing.T) {
+	a, err := CrossValidation{K: 4, Seed: 3}.KFold(50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := CrossValidation{K: 4, Seed: 3}.KFold(50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Fatal("the same seed gave different folds")
+	}
+	c, err := CrossValidation{K: 4, Seed: 4}.KFold(50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reflect.DeepEqual(a, c) {
+		t.Fatal("different seeds gave the same folds")
+	}
+
+	//the repeats are shuffled differently
+	repeated, err := CrossValidation{K: 4, Repeats: 2, Seed: 3}.KFold(50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reflect.DeepEqual(repeated[:4], repeated[4:]) {
+		t.Fatal("both repeats have the same folds")
+	}
+}
+
+func TestStratifiedKFold(t *testing.T) {
+	//12 rows of class 0, 6 of class 1 and 3 of class 2
+	var y []float64
+	for class, n := range []int{12, 6, 3} {
+		for r := 0; r < n; r++ {
+			y = append(y, float64(class))
+		}
+	}
+	folds, err := CrossValidation{K: 3, Seed: 1}.StratifiedKFold(y, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkFolds(t, folds, len(y), 3)
+	for i, fold := range folds {
+		counts := make([]int, 3)
+		for _, r := range fold.Test {
+			counts[int(y[r])]++
+		}
+		if !reflect.DeepEqual(counts, []int{4, 2, 1}) {
+			t.Fatalf("fold %d tests %v rows of each class, want [4 2 1]", i, counts)
+		}
+	}
+
+	again, err := CrossValidation{K: 3, Seed: 1}.StratifiedKFold(y, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(folds, again) {
+		t.Fatal("the same seed gave different folds")
+	}
+
+	_, err = CrossValidation{K: 22}.StratifiedKFold(y, 0)
+	assertErrorContains(t, err, "can't make 22 folds of 21 rows")
+}
+
+func TestLeaveOneOut(t *testing.T) {
+	folds := LeaveOneOut(6)
+	if len(folds) != 6 {
+		t.Fatalf("got %d folds, want 6", len(folds))
+	}
+	checkFolds(t, folds, 6, 6)
+	for i, fold := range folds {
+		if !reflect.DeepEqual(fold.Test, []int{i}) {
+			t.Fatalf("fold %d tests %v, want [%d]", i, fold.Test, i)
+		}
+	}
+}
+
+// meanModel predicts the mean of the targets it was fit on.
+type meanModel struct {
+	mean []float64
+}
+
+func (m *meanModel) Fit(X [][]float64, T [][]float64) error {
+	m.mean = MeanByColumn(T)
+	return nil
+}
+
+func (m *meanModel) Predict(X [][]float64) [][]float64 {
+	result := make([][]float64, len(X))
+	for r := range result {
+		result[r] = append([]float64(nil), m.mean...)
+	}
+	return result
+}
+
+func TestCrossValidate(t *testing.T) {
+	X := [][]float64{{0}, {1}, {2}, {3}, {4}, {5}}
+	T := [][]float64{{1, 10}, {1, 10}, {1, 10}, {3, 10}, {3, 10}, {3, 10}}
+	folds := []Fold{
+		{Train: []int{0, 1, 2}, Test: []int{3, 4, 5}},
+		{Train: []int{3, 4, 5}, Test: []int{0, 1, 2}},
+		{Train: []int{0, 1, 3, 4}, Test: []int{2, 5}},
+	}
+	result, err := CrossValidate(func() Model { return &meanModel{} }, X, T, folds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Folds) != len(folds) {
+		t.Fatalf("got %d scores, want one for each of the %d folds", len(result.Folds), len(folds))
+	}
+	//the model only sees the training rows of a fold, so the first two are 2 off
+	want := [][]float64{{2, 0}, {2, 0}, {1, 0}}
+	for i, score := range result.Folds {
+		assertClose(t, fmt.Sprintf("fold %d TestRMSE", i), [][]float64{score.TestRMSE}, want[i:i+1], 1e-12)
+	}
+	assertClose(t, "MeanRMSE", [][]float64{result.MeanRMSE}, [][]float64{{5.0 / 3, 0}}, 1e-12)
+
+	_, err = CrossValidate(func() Model { return &meanModel{} }, X, T, nil)
+	assertErrorContains(t, err, "no folds")
+}
@@ -0,0 +1,152 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ReadCSV reads a comma separated file of numbers and returns the columns from
+// beginColumn through endColumn (inclusive) of every line.
+func ReadCSV(filename string, beginColumn int, endColumn int) ([][]float64, error) {
+	var result [][]float64
+
+	records, err := readRecords(filename, CSVOptions{})
+	if err != nil {
+		return result, err
+	}
+	missing := missingSet(nil)
+
+	for _, rec := range records {
+		if endColumn >= len(rec.fields) {
+			return result, &ParseError{Filename: filename, Line: rec.line, Column: len(rec.fields) + 1,
+				Err: fmt.Errorf("expected %d columns, found %d", endColumn+1, len(rec.fields))}
+		}
+
+		var data []float64
+
+		//only get columns desired.
+		for c := beginColumn; c <= endColumn; c++ {
+			//convert from string to float64
+			f, err := parseValue(rec.fields[c], missing)
+			if err != nil {
+				return result, &ParseError{Filename: filename, Line: rec.line, Column: c + 1, Err: err}
+			}
+
+			data = append(data, f)
+		}
+		result = append(result, data)
+	}
+
+	return result, nil
+}
+
+// readRecords reads every record of a CSV file.
+func readRecords(filename string, options CSVOptions) ([]record, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	records, err := newCSVReader(file, options).readAll()
+	if e, ok := err.(*ParseError); ok {
+		e.Filename = filename
+	}
+	return records, err
+}
+
+// DefaultMissingValues are the fields that are read as a missing value (NaN)
+// when CSVOptions.MissingValues is not set.
+var DefaultMissingValues = []string{"", "NA", "N/A", "NaN", "?"}
+
+// missingSet returns the set of fields that mark a missing value.
+func missingSet(values []string) map[string]bool {
+	if values == nil {
+		values = DefaultMissingValues
+	}
+	result := make(map[string]bool, len(values))
+	for _, v := range values {
+		result[strings.TrimSpace(v)] = true
+	}
+	return result
+}
+
+// parseValue converts a field to a number, ignoring any spaces around it.
+// Fields in the missing set become NaN.
+func parseValue(field string, missing map[string]bool) (float64, error) {
+	field = strings.TrimSpace(field)
+	if missing[field] {
+		return math.NaN(), nil
+	}
+	return strconv.ParseFloat(field, 64)
+}
+
+// IsMissing reports whether v is a missing value.
+func IsMissing(v float64) bool {
+	return math.IsNaN(v)
+}
+
+// MissingByColumn returns the number of missing values in every column of X,
+// or nil when X has no rows.
+func MissingByColumn(X [][]float64) []int {
+	if len(X) == 0 {
+		return nil
+	}
+	result := make([]int, len(X[0]))
+	for r := range X {
+		for c, v := range X[r] {
+			if IsMissing(v) {
+				result[c]++
+			}
+		}
+	}
+	return result
+}
+
+// ColumnRange returns the column indexes from begin through end (inclusive).
+func ColumnRange(begin int, end int) []int {
+	var result []int
+	for c := begin; c <= end; c++ {
+		result = append(result, c)
+	}
+	return result
+}
+
+// SelectColumns returns a new matrix made of the given columns of data, in the
+// order they are listed.
+func SelectColumns(data [][]float64, columns []int) [][]float64 {
+	result := make([][]float64, len(data))
+	for r := range data {
+		row := make([]float64, len(columns))
+		for i, c := range columns {
+			row[i] = data[r][c]
+		}
+		result[r] = row
+	}
+	return result
+}
+
+// Column returns column c of X.
+func Column(X [][]float64, c int) []float64 {
+	result := make([]float64, len(X))
+	for r := range X {
+		result[r] = X[r][c]
+	}
+	return result
+}
+
+// AddBias returns a copy of X with a column of 1's added in front, this is
+// required so that the first row of the weight matrix acts as the intercept.
+func AddBias(X [][]float64) [][]float64 {
+	result := make([][]float64, len(X))
+	for r := range X {
+		row := make([]float64, 0, len(X[r])+1)
+		row = append(row, 1.0)
+		row = append(row, X[r]...)
+		result[r] = row
+	}
+	return result
+}
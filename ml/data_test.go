@@ -0,0 +1,99 @@
+package ml
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTemp writes text to a file in a new temporary directory and returns
+// its name.  The directory is removed when the test ends.
+func writeTemp(t *testing.T, name string, text string) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "ml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	filename := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(filename, []byte(text), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestReadCSV(t *testing.T) {
+	filename := writeTemp(t, "data.csv", "7.4,0.7,0,5\n7.8,0.88,0,5\r\n\n11.2,0.28,0.56,6\n")
+	tests := []struct {
+		name       string
+		begin, end int
+		want       [][]float64
+	}{
+		{"all", 0, 3, [][]float64{{7.4, 0.7, 0, 5}, {7.8, 0.88, 0, 5}, {11.2, 0.28, 0.56, 6}}},
+		{"last", 3, 3, [][]float64{{5}, {5}, {6}}},
+		{"middle", 1, 2, [][]float64{{0.7, 0}, {0.88, 0}, {0.28, 0.56}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ReadCSV(filename, test.begin, test.end)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertClose(t, "ReadCSV", got, test.want, 0)
+		})
+	}
+}
+
+func TestReadCSVMissing(t *testing.T) {
+	filename := writeTemp(t, "data.csv", "1,NA\n?,4\n")
+	got, err := ReadCSV(filename, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[0][0] != 1 || !math.IsNaN(got[0][1]) || !math.IsNaN(got[1][0]) || got[1][1] != 4 {
+		t.Fatalf("ReadCSV = %v, want [[1 NaN] [NaN 4]]", got)
+	}
+	if missing := MissingByColumn(got); missing[0] != 1 || missing[1] != 1 {
+		t.Fatalf("MissingByColumn = %v, want [1 1]", missing)
+	}
+}
+
+func TestMissingByColumnEmpty(t *testing.T) {
+	if missing := MissingByColumn(nil); len(missing) != 0 {
+		t.Fatalf("MissingByColumn(nil) = %v, want nothing", missing)
+	}
+}
+
+func TestReadCSVErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		end    int
+		line   int
+		column int
+	}{
+		{"short line", "1,2,3\n4,5\n", 2, 2, 3},
+		{"not a number", "1,2\n3,x\n", 1, 2, 2},
+		{"unclosed quote", "1,2\n\"3,4\n", 1, 2, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filename := writeTemp(t, "data.csv", test.text)
+			_, err := ReadCSV(filename, 0, test.end)
+			parseErr, ok := err.(*ParseError)
+			if !ok {
+				t.Fatalf("got error %v, want a *ParseError", err)
+			}
+			if parseErr.Filename != filename || parseErr.Line != test.line || parseErr.Column != test.column {
+				t.Fatalf("got %s, want line %d, column %d", parseErr, test.line, test.column)
+			}
+		})
+	}
+
+	_, err := ReadCSV(filepath.Join(os.TempDir(), "no such file.csv"), 0, 0)
+	if !os.IsNotExist(err) {
+		t.Fatalf("got error %v, want one for a missing file", err)
+	}
+}
@@ -0,0 +1,317 @@
+package ml
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Header tells LoadCSV whether the first line of a file holds column names.
+type Header int
+
+const (
+	//HeaderAuto treats the first line as a header when any of the fields
+	//that are loaded as numbers is not a number, or when Columns or
+	//Categorical are set, when it holds their names.
+	HeaderAuto Header = iota
+	//HeaderPresent always treats the first line as a header.
+	HeaderPresent
+	//HeaderAbsent treats every line as data.
+	HeaderAbsent
+)
+
+// CSVOptions configures LoadCSV.  The zero value reads a comma separated file,
+// detects the header and loads every column.
+type CSVOptions struct {
+	Header Header
+
+	//Delimiter separates the fields of a line, it defaults to ','.
+	Delimiter rune
+	//Quote surrounds fields that hold the delimiter or line breaks, it
+	//defaults to '"'.
+	Quote rune
+	//Comment, when set, marks lines to skip when it is their first character.
+	Comment rune
+
+	//MissingValues are the fields that mark a missing value, which is loaded
+	//as NaN.  When nil DefaultMissingValues is used, set it to an empty slice
+	//to require every field to be a number.
+	MissingValues []string
+
+	//Names are the column names to use when the file has no header.  When
+	//empty the columns are named "column 1", "column 2" and so on, counting
+	//from 1 like the columns of a ParseError.
+	Names []string
+
+	//Columns limits loading to the named columns, in the order given.  Columns
+	//that are not loaded do not need to hold numbers.
+	Columns []string
+	//ColumnIndexes limits loading to the columns at these indexes, in the order
+	//given.  It is ignored when Columns is set.
+	ColumnIndexes []int
+
+	//Categorical are the names of the columns to load as text into
+	//Dataset.Categories instead of as numbers.  When Columns or ColumnIndexes
+	//are set, a categorical column is only loaded if it is one of them.
+	Categorical []string
+}
+
+// Dataset is a matrix of data that remembers the name of each of its columns.
+// Categorical (text) columns are kept apart from the numbers, with one row of
+// Categories for every row of Data.
+type Dataset struct {
+	Names []string
+	Data  [][]float64
+
+	CategoryNames []string
+	Categories    [][]string
+}
+
+// LoadCSV reads a CSV file into a Dataset.
+func LoadCSV(filename string, options CSVOptions) (*Dataset, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	result, err := ReadDataset(file, options)
+	if e, ok := err.(*ParseError); ok {
+		e.Filename = filename
+	} else if err != nil {
+		err = fmt.Errorf("%s: %v", filename, err)
+	}
+	return result, err
+}
+
+// ReadDataset reads CSV text into a Dataset.
+func ReadDataset(r io.Reader, options CSVOptions) (*Dataset, error) {
+	records, err := newCSVReader(r, options).readAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no data")
+	}
+
+	missing := missingSet(options.MissingValues)
+	hasHeader := options.Header == HeaderPresent
+	if options.Header == HeaderAuto {
+		hasHeader = isHeader(records[0].fields, options, missing)
+	}
+
+	var names []string
+	switch {
+	case hasHeader:
+		names = trimAll(records[0].fields)
+		records = records[1:]
+	case len(options.Names) > 0:
+		if len(options.Names) != len(records[0].fields) {
+			return nil, fmt.Errorf("%d names given for %d columns", len(options.Names), len(records[0].fields))
+		}
+		names = options.Names
+	default:
+		for c := range records[0].fields {
+			names = append(names, fmt.Sprintf("column %d", c+1))
+		}
+	}
+
+	all := &Dataset{Names: names}
+	categorical, err := all.Indexes(options.Categorical...)
+	if err != nil {
+		return nil, err
+	}
+	selected := options.ColumnIndexes
+	if len(options.Columns) > 0 {
+		if selected, err = all.Indexes(options.Columns...); err != nil {
+			return nil, err
+		}
+	}
+	if len(selected) == 0 {
+		selected = ColumnRange(0, len(names)-1)
+	}
+	isSelected := make(map[int]bool, len(selected))
+	for _, c := range selected {
+		if c < 0 || c >= len(names) {
+			return nil, fmt.Errorf("column index %d out of range", c)
+		}
+		isSelected[c] = true
+	}
+
+	result := &Dataset{}
+	//categorical columns that were not selected are left out like any other
+	isCategorical := make(map[int]bool, len(categorical))
+	var loadedCategorical []int
+	for _, c := range categorical {
+		isCategorical[c] = true
+		if isSelected[c] {
+			loadedCategorical = append(loadedCategorical, c)
+			result.CategoryNames = append(result.CategoryNames, names[c])
+		}
+	}
+	categorical = loadedCategorical
+	var columns []int
+	for _, c := range selected {
+		if !isCategorical[c] {
+			columns = append(columns, c)
+			result.Names = append(result.Names, names[c])
+		}
+	}
+	for _, rec := range records {
+		if len(rec.fields) != len(names) {
+			return nil, &ParseError{Line: rec.line, Column: len(rec.fields) + 1,
+				Err: fmt.Errorf("found %d columns, expected %d", len(rec.fields), len(names))}
+		}
+		row := make([]float64, len(columns))
+		for i, c := range columns {
+			f, err := parseValue(rec.fields[c], missing)
+			if err != nil {
+				return nil, &ParseError{Line: rec.line, Column: c + 1, Name: names[c], Err: err}
+			}
+			row[i] = f
+		}
+		result.Data = append(result.Data, row)
+
+		if len(categorical) > 0 {
+			categories := make([]string, len(categorical))
+			for i, c := range categorical {
+				categories[i] = strings.TrimSpace(rec.fields[c])
+			}
+			result.Categories = append(result.Categories, categories)
+		}
+	}
+	return result, nil
+}
+
+// isHeader reports whether the first line of a file, fields, looks like a
+// header for HeaderAuto.  When columns are picked by name it is a header when
+// it holds those names, otherwise it is a header when any of the selected
+// columns that should be numbers is not one.
+func isHeader(fields []string, options CSVOptions, missing map[string]bool) bool {
+	named := append(append([]string(nil), options.Columns...), options.Categorical...)
+	if len(named) > 0 {
+		first := &Dataset{Names: trimAll(fields)}
+		_, err := first.Indexes(named...)
+		return err == nil
+	}
+	columns := options.ColumnIndexes
+	if len(columns) == 0 {
+		columns = ColumnRange(0, len(fields)-1)
+	}
+	for _, c := range columns {
+		if c < 0 || c >= len(fields) {
+			continue
+		}
+		if _, err := parseValue(fields[c], missing); err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// trimAll returns fields with the spaces around each of them removed.
+func trimAll(fields []string) []string {
+	result := make([]string, len(fields))
+	for i, v := range fields {
+		result[i] = strings.TrimSpace(v)
+	}
+	return result
+}
+
+// Index returns the index of the column with the given name.
+func (d *Dataset) Index(name string) (int, error) {
+	for c, v := range d.Names {
+		if v == name {
+			return c, nil
+		}
+	}
+	return -1, fmt.Errorf("no column named %q", name)
+}
+
+// Indexes returns the index of every named column.
+func (d *Dataset) Indexes(names ...string) ([]int, error) {
+	result := make([]int, len(names))
+	for i, name := range names {
+		c, err := d.Index(name)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = c
+	}
+	return result, nil
+}
+
+// Select returns a new Dataset made of the columns at the given indexes.
+func (d *Dataset) Select(columns []int) *Dataset {
+	result := &Dataset{
+		Data:          SelectColumns(d.Data, columns),
+		CategoryNames: d.CategoryNames,
+		Categories:    d.Categories,
+	}
+	for _, c := range columns {
+		result.Names = append(result.Names, d.Names[c])
+	}
+	return result
+}
+
+// Category returns the values of the named categorical column.
+func (d *Dataset) Category(name string) ([]string, error) {
+	for c, v := range d.CategoryNames {
+		if v == name {
+			result := make([]string, len(d.Categories))
+			for r := range d.Categories {
+				result[r] = d.Categories[r][c]
+			}
+			return result, nil
+		}
+	}
+	return nil, fmt.Errorf("no categorical column named %q", name)
+}
+
+// SelectNames returns a new Dataset made of the named columns.
+func (d *Dataset) SelectNames(names ...string) (*Dataset, error) {
+	columns, err := d.Indexes(names...)
+	if err != nil {
+		return nil, err
+	}
+	return d.Select(columns), nil
+}
+
+// Targets splits the dataset into the named target columns and the feature
+// columns, which are all of the other columns in their original order.
+func (d *Dataset) Targets(names ...string) (features *Dataset, targets *Dataset, err error) {
+	targetColumns, err := d.Indexes(names...)
+	if err != nil {
+		return nil, nil, err
+	}
+	isTarget := make(map[int]bool, len(targetColumns))
+	for _, c := range targetColumns {
+		isTarget[c] = true
+	}
+	var featureColumns []int
+	for c := range d.Names {
+		if !isTarget[c] {
+			featureColumns = append(featureColumns, c)
+		}
+	}
+	targets = d.Select(targetColumns)
+	targets.CategoryNames, targets.Categories = nil, nil
+	return d.Select(featureColumns), targets, nil
+}
+
+// Rows returns a new Dataset made of the rows at the given indexes.
+func (d *Dataset) Rows(indexes []int) *Dataset {
+	result := &Dataset{
+		Names:         d.Names,
+		Data:          Rows(d.Data, indexes),
+		CategoryNames: d.CategoryNames,
+	}
+	if d.Categories != nil {
+		result.Categories = make([][]string, len(indexes))
+		for i, r := range indexes {
+			result.Categories[i] = d.Categories[r]
+		}
+	}
+	return result
+}
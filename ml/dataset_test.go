@@ -0,0 +1,168 @@
+package ml
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadDataset(t *testing.T) {
+	tests := []struct {
+		name       string
+		text       string
+		options    CSVOptions
+		names      []string
+		data       [][]float64
+		categories [][]string
+	}{
+		{
+			name:  "header detected",
+			text:  "a,b,c\n1,2,3\n4,5,6\n",
+			names: []string{"a", "b", "c"},
+			data:  [][]float64{{1, 2, 3}, {4, 5, 6}},
+		},
+		{
+			name:  "no header detected",
+			text:  "1,2,3\n4,5,6\n",
+			names: []string{"column 1", "column 2", "column 3"},
+			data:  [][]float64{{1, 2, 3}, {4, 5, 6}},
+		},
+		{
+			name:    "names given",
+			text:    "1,2\n3,4\n",
+			options: CSVOptions{Header: HeaderAbsent, Names: []string{"x", "y"}},
+			names:   []string{"x", "y"},
+			data:    [][]float64{{1, 2}, {3, 4}},
+		},
+		{
+			name:    "columns by name in the order given",
+			text:    "a,b,c\n1,2,3\n4,5,6\n",
+			options: CSVOptions{Columns: []string{"c", "a"}},
+			names:   []string{"c", "a"},
+			data:    [][]float64{{3, 1}, {6, 4}},
+		},
+		{
+			name:    "columns by index",
+			text:    "a,b,c\n1,2,3\n4,5,6\n",
+			options: CSVOptions{ColumnIndexes: []int{1}},
+			names:   []string{"b"},
+			data:    [][]float64{{2}, {5}},
+		},
+		{
+			name:    "semicolons, quotes and comments",
+			text:    "# wine\n\"a\";\"b\"\n\"1\";2\n# more\n3;\"4\"\n",
+			options: CSVOptions{Delimiter: ';', Comment: '#'},
+			names:   []string{"a", "b"},
+			data:    [][]float64{{1, 2}, {3, 4}},
+		},
+		{
+			name:       "categorical",
+			text:       "color,x\nred,1\n blue ,2\n",
+			options:    CSVOptions{Categorical: []string{"color"}},
+			names:      []string{"x"},
+			data:       [][]float64{{1}, {2}},
+			categories: [][]string{{"red"}, {"blue"}},
+		},
+		{
+			name:    "text in a column that is not loaded",
+			text:    "1,2,x\n3,4,y\n",
+			options: CSVOptions{ColumnIndexes: []int{0, 1}},
+			names:   []string{"column 1", "column 2"},
+			data:    [][]float64{{1, 2}, {3, 4}},
+		},
+		{
+			name:       "only the selected categorical columns",
+			text:       "color,size,x\nred,big,1\nblue,small,2\n",
+			options:    CSVOptions{Columns: []string{"x", "size"}, Categorical: []string{"color", "size"}},
+			names:      []string{"x"},
+			data:       [][]float64{{1}, {2}},
+			categories: [][]string{{"big"}, {"small"}},
+		},
+		{
+			name:    "no categorical columns selected",
+			text:    "color,x\nred,1\nblue,2\n",
+			options: CSVOptions{Columns: []string{"x"}, Categorical: []string{"color"}},
+			names:   []string{"x"},
+			data:    [][]float64{{1}, {2}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d, err := ReadDataset(strings.NewReader(test.text), test.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(d.Names, test.names) {
+				t.Fatalf("Names = %q, want %q", d.Names, test.names)
+			}
+			assertClose(t, "Data", d.Data, test.data, 0)
+			if !reflect.DeepEqual(d.Categories, test.categories) {
+				t.Fatalf("Categories = %q, want %q", d.Categories, test.categories)
+			}
+		})
+	}
+}
+
+func TestReadDatasetMissing(t *testing.T) {
+	d, err := ReadDataset(strings.NewReader("a,b\n1,NA\n,4\n5,-\n"), CSVOptions{MissingValues: []string{"", "NA", "-"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !math.IsNaN(d.Data[0][1]) || !math.IsNaN(d.Data[1][0]) || !math.IsNaN(d.Data[2][1]) {
+		t.Fatalf("Data = %v, want NaN for every missing value", d.Data)
+	}
+
+	//with no missing values allowed an empty field is an error
+	_, err = ReadDataset(strings.NewReader("a,b\n1,\n"), CSVOptions{MissingValues: []string{}})
+	if _, ok := err.(*ParseError); !ok {
+		t.Fatalf("got error %v, want a *ParseError", err)
+	}
+}
+
+func TestReadDatasetErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		options CSVOptions
+		line    int
+		column  int
+	}{
+		{"not a number", "a,b\n1,2\n3,x\n", CSVOptions{}, 3, 2},
+		{"short line", "a,b,c\n1,2,3\n4,5\n", CSVOptions{}, 3, 3},
+		{"long line", "a,b\n1,2\n3,4,5\n", CSVOptions{}, 3, 4},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ReadDataset(strings.NewReader(test.text), test.options)
+			parseErr, ok := err.(*ParseError)
+			if !ok {
+				t.Fatalf("got error %v, want a *ParseError", err)
+			}
+			if parseErr.Line != test.line || parseErr.Column != test.column {
+				t.Fatalf("got %s, want line %d, column %d", parseErr, test.line, test.column)
+			}
+		})
+	}
+
+	_, err := ReadDataset(strings.NewReader("a,b\n1,2\n"), CSVOptions{Columns: []string{"c"}})
+	assertErrorContains(t, err, `no column named "c"`)
+	_, err = ReadDataset(strings.NewReader(""), CSVOptions{})
+	assertErrorContains(t, err, "no data")
+}
+
+func TestDatasetTargets(t *testing.T) {
+	d, err := ReadDataset(strings.NewReader("a,b,c\n1,2,3\n4,5,6\n"), CSVOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	features, targets, err := d.Targets("b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(features.Names, []string{"a", "c"}) || !reflect.DeepEqual(targets.Names, []string{"b"}) {
+		t.Fatalf("got features %q and targets %q", features.Names, targets.Names)
+	}
+	assertClose(t, "features", features.Data, [][]float64{{1, 3}, {4, 6}}, 0)
+	assertClose(t, "targets", targets.Data, [][]float64{{2}, {5}}, 0)
+}
@@ -0,0 +1,615 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// epsilon is the spacing of float64 values around 1, used to decide when a
+// value is too small to be told apart from zero.
+const epsilon = 2.220446049250313e-16
+
+// QR is the QR decomposition of an m x n matrix A with m >= n, A = Q R, where
+// Q has orthonormal columns and R is upper triangular.  It is computed with
+// Householder reflections.
+type QR struct {
+	qr    [][]float64
+	rDiag []float64
+	m, n  int
+}
+
+// NewQR decomposes A, which is not modified.
+func NewQR(A [][]float64) (*QR, error) {
+	m := len(A)
+	if m == 0 {
+		return nil, fmt.Errorf("qr: empty matrix")
+	}
+	n := len(A[0])
+	if m < n {
+		return nil, fmt.Errorf("qr: matrix has fewer rows (%d) than columns (%d)", m, n)
+	}
+
+	qr := Copy(A)
+	rDiag := make([]float64, n)
+	for k := 0; k < n; k++ {
+		//norm of the k-th column below the diagonal
+		norm := 0.0
+		for i := k; i < m; i++ {
+			norm = math.Hypot(norm, qr[i][k])
+		}
+		if norm != 0.0 {
+			//form the k-th Householder vector
+			if qr[k][k] < 0 {
+				norm = -norm
+			}
+			for i := k; i < m; i++ {
+				qr[i][k] /= norm
+			}
+			qr[k][k] += 1.0
+
+			//apply it to the remaining columns
+			for j := k + 1; j < n; j++ {
+				s := 0.0
+				for i := k; i < m; i++ {
+					s += qr[i][k] * qr[i][j]
+				}
+				s = -s / qr[k][k]
+				for i := k; i < m; i++ {
+					qr[i][j] += s * qr[i][k]
+				}
+			}
+		}
+		rDiag[k] = -norm
+	}
+	return &QR{qr: qr, rDiag: rDiag, m: m, n: n}, nil
+}
+
+// IsFullRank reports whether the columns of A are linearly independent.
+func (f *QR) IsFullRank() bool {
+	largest := 0.0
+	for _, v := range f.rDiag {
+		largest = math.Max(largest, math.Abs(v))
+	}
+	tolerance := float64(f.m) * epsilon * largest
+	for _, v := range f.rDiag {
+		if math.Abs(v) <= tolerance {
+			return false
+		}
+	}
+	return true
+}
+
+// R returns the n x n upper triangular factor.
+func (f *QR) R() [][]float64 {
+	result := Zeros(f.n, f.n)
+	for i := 0; i < f.n; i++ {
+		result[i][i] = f.rDiag[i]
+		for j := i + 1; j < f.n; j++ {
+			result[i][j] = f.qr[i][j]
+		}
+	}
+	return result
+}
+
+// Q returns the m x n factor with orthonormal columns.
+func (f *QR) Q() [][]float64 {
+	result := Zeros(f.m, f.n)
+	for k := f.n - 1; k >= 0; k-- {
+		result[k][k] = 1.0
+		for j := k; j < f.n; j++ {
+			if f.qr[k][k] != 0 {
+				s := 0.0
+				for i := k; i < f.m; i++ {
+					s += f.qr[i][k] * result[i][j]
+				}
+				s = -s / f.qr[k][k]
+				for i := k; i < f.m; i++ {
+					result[i][j] += s * f.qr[i][k]
+				}
+			}
+		}
+	}
+	return result
+}
+
+// Solve returns the n x p matrix X that minimizes the squared error of A X
+// against the m x p matrix B.
+func (f *QR) Solve(B [][]float64) ([][]float64, error) {
+	if len(B) != f.m {
+		return nil, fmt.Errorf("qr: right hand side has %d rows, expected %d", len(B), f.m)
+	}
+	if !f.IsFullRank() {
+		return nil, fmt.Errorf("qr: matrix is rank deficient")
+	}
+
+	p := len(B[0])
+	x := Copy(B)
+
+	//compute Q^T B
+	for k := 0; k < f.n; k++ {
+		for j := 0; j < p; j++ {
+			s := 0.0
+			for i := k; i < f.m; i++ {
+				s += f.qr[i][k] * x[i][j]
+			}
+			s = -s / f.qr[k][k]
+			for i := k; i < f.m; i++ {
+				x[i][j] += s * f.qr[i][k]
+			}
+		}
+	}
+
+	//solve R X = Q^T B by back substitution
+	for k := f.n - 1; k >= 0; k-- {
+		for j := 0; j < p; j++ {
+			x[k][j] /= f.rDiag[k]
+		}
+		for i := 0; i < k; i++ {
+			for j := 0; j < p; j++ {
+				x[i][j] -= x[k][j] * f.qr[i][k]
+			}
+		}
+	}
+	return x[:f.n], nil
+}
+
+// Cholesky is the Cholesky decomposition of a symmetric positive definite
+// matrix A, A = L L^T, where L is lower triangular.
+type Cholesky struct {
+	l [][]float64
+}
+
+// NewCholesky decomposes A, which is not modified.  Only the lower triangle
+// of A is used.
+func NewCholesky(A [][]float64) (*Cholesky, error) {
+	n := len(A)
+	if n == 0 || len(A[0]) != n {
+		return nil, fmt.Errorf("cholesky: matrix is not square")
+	}
+	l := Zeros(n, n)
+	for j := 0; j < n; j++ {
+		d := A[j][j]
+		for k := 0; k < j; k++ {
+			d -= l[j][k] * l[j][k]
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("cholesky: matrix is not positive definite")
+		}
+		l[j][j] = math.Sqrt(d)
+		for i := j + 1; i < n; i++ {
+			s := A[i][j]
+			for k := 0; k < j; k++ {
+				s -= l[i][k] * l[j][k]
+			}
+			l[i][j] = s / l[j][j]
+		}
+	}
+	return &Cholesky{l: l}, nil
+}
+
+// L returns the lower triangular factor.
+func (f *Cholesky) L() [][]float64 {
+	return Copy(f.l)
+}
+
+// Solve returns X such that A X = B.
+func (f *Cholesky) Solve(B [][]float64) ([][]float64, error) {
+	n := len(f.l)
+	if len(B) != n {
+		return nil, fmt.Errorf("cholesky: right hand side has %d rows, expected %d", len(B), n)
+	}
+	x := Copy(B)
+	p := len(B[0])
+
+	//solve L Y = B
+	for k := 0; k < n; k++ {
+		for j := 0; j < p; j++ {
+			for i := 0; i < k; i++ {
+				x[k][j] -= x[i][j] * f.l[k][i]
+			}
+			x[k][j] /= f.l[k][k]
+		}
+	}
+	//solve L^T X = Y
+	for k := n - 1; k >= 0; k-- {
+		for j := 0; j < p; j++ {
+			for i := k + 1; i < n; i++ {
+				x[k][j] -= x[i][j] * f.l[i][k]
+			}
+			x[k][j] /= f.l[k][k]
+		}
+	}
+	return x, nil
+}
+
+// LU is the LU decomposition with partial pivoting of a square matrix A,
+// P A = L U, where L is unit lower triangular, U is upper triangular and P
+// is the permutation given by Pivot.
+type LU struct {
+	lu    [][]float64
+	pivot []int
+	sign  float64
+}
+
+// NewLU decomposes A, which is not modified.
+func NewLU(A [][]float64) (*LU, error) {
+	n := len(A)
+	if n == 0 || len(A[0]) != n {
+		return nil, fmt.Errorf("lu: matrix is not square")
+	}
+	lu := Copy(A)
+	pivot := ColumnRange(0, n-1)
+	sign := 1.0
+	for k := 0; k < n; k++ {
+		//find the largest value in the column to pivot on
+		p := k
+		for i := k + 1; i < n; i++ {
+			if math.Abs(lu[i][k]) > math.Abs(lu[p][k]) {
+				p = i
+			}
+		}
+		if p != k {
+			lu[p], lu[k] = lu[k], lu[p]
+			pivot[p], pivot[k] = pivot[k], pivot[p]
+			sign = -sign
+		}
+		if lu[k][k] == 0 {
+			continue
+		}
+		for i := k + 1; i < n; i++ {
+			lu[i][k] /= lu[k][k]
+			for j := k + 1; j < n; j++ {
+				lu[i][j] -= lu[i][k] * lu[k][j]
+			}
+		}
+	}
+	return &LU{lu: lu, pivot: pivot, sign: sign}, nil
+}
+
+// IsSingular reports whether A has no inverse, which is when a diagonal value
+// of U is below the tolerance.  Rounding seldom leaves an exact zero there.
+func (f *LU) IsSingular() bool {
+	tolerance := f.tolerance()
+	for i := range f.lu {
+		if math.Abs(f.lu[i][i]) <= tolerance {
+			return true
+		}
+	}
+	return false
+}
+
+// tolerance is the size below which a diagonal value of U is treated as zero.
+func (f *LU) tolerance() float64 {
+	largest := 0.0
+	for i := range f.lu {
+		for j := i; j < len(f.lu); j++ {
+			largest = math.Max(largest, math.Abs(f.lu[i][j]))
+		}
+	}
+	return float64(len(f.lu)) * epsilon * largest
+}
+
+// L returns the unit lower triangular factor.
+func (f *LU) L() [][]float64 {
+	n := len(f.lu)
+	result := Identity(n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < i; j++ {
+			result[i][j] = f.lu[i][j]
+		}
+	}
+	return result
+}
+
+// U returns the upper triangular factor.
+func (f *LU) U() [][]float64 {
+	n := len(f.lu)
+	result := Zeros(n, n)
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			result[i][j] = f.lu[i][j]
+		}
+	}
+	return result
+}
+
+// Pivot returns the row of A that ended up in each row of L U.
+func (f *LU) Pivot() []int {
+	return append([]int(nil), f.pivot...)
+}
+
+// Det returns the determinant of A.
+func (f *LU) Det() float64 {
+	result := f.sign
+	for i := range f.lu {
+		result *= f.lu[i][i]
+	}
+	return result
+}
+
+// Solve returns X such that A X = B.
+func (f *LU) Solve(B [][]float64) ([][]float64, error) {
+	n := len(f.lu)
+	if len(B) != n {
+		return nil, fmt.Errorf("lu: right hand side has %d rows, expected %d", len(B), n)
+	}
+	if f.IsSingular() {
+		return nil, fmt.Errorf("lu: matrix is singular")
+	}
+	x := Rows(Copy(B), f.pivot)
+	p := len(B[0])
+
+	//solve L Y = P B
+	for k := 0; k < n; k++ {
+		for i := k + 1; i < n; i++ {
+			for j := 0; j < p; j++ {
+				x[i][j] -= x[k][j] * f.lu[i][k]
+			}
+		}
+	}
+	//solve U X = Y
+	for k := n - 1; k >= 0; k-- {
+		for j := 0; j < p; j++ {
+			x[k][j] /= f.lu[k][k]
+		}
+		for i := 0; i < k; i++ {
+			for j := 0; j < p; j++ {
+				x[i][j] -= x[k][j] * f.lu[i][k]
+			}
+		}
+	}
+	return x, nil
+}
+
+// Inverse returns the inverse of A.
+func (f *LU) Inverse() ([][]float64, error) {
+	return f.Solve(Identity(len(f.lu)))
+}
+
+// SVD is the thin singular value decomposition of an m x n matrix A,
+// A = U diag(S) V^T, with k = min(m, n) singular values in decreasing order.
+// U is m x k and V is n x k, both with orthonormal columns, except that the
+// columns of U for singular values of zero are left as zeros.
+//
+// It is computed with one-sided Jacobi rotations, which is slow for large
+// matrices but very accurate.
+type SVD struct {
+	U [][]float64
+	S []float64
+	V [][]float64
+}
+
+// NewSVD decomposes A, which is not modified.
+func NewSVD(A [][]float64) (*SVD, error) {
+	if len(A) == 0 || len(A[0]) == 0 {
+		return nil, fmt.Errorf("svd: empty matrix")
+	}
+	if len(A) < len(A[0]) {
+		//decompose the transpose and swap U and V
+		result, err := NewSVD(Transpose(A))
+		if err != nil {
+			return nil, err
+		}
+		result.U, result.V = result.V, result.U
+		return result, nil
+	}
+
+	m, n := len(A), len(A[0])
+	u := Copy(A)
+	v := Identity(n)
+	for sweep := 0; sweep < 100; sweep++ {
+		rotated := false
+		for i := 0; i < n-1; i++ {
+			for j := i + 1; j < n; j++ {
+				alpha, beta, gamma := 0.0, 0.0, 0.0
+				for r := 0; r < m; r++ {
+					alpha += u[r][i] * u[r][i]
+					beta += u[r][j] * u[r][j]
+					gamma += u[r][i] * u[r][j]
+				}
+				if gamma == 0 || math.Abs(gamma) <= epsilon*math.Sqrt(alpha*beta) {
+					continue
+				}
+				rotated = true
+
+				//rotate columns i and j so that they become orthogonal
+				zeta := (beta - alpha) / (2 * gamma)
+				t := math.Copysign(1, zeta) / (math.Abs(zeta) + math.Sqrt(1+zeta*zeta))
+				c := 1 / math.Sqrt(1+t*t)
+				s := c * t
+				for r := 0; r < m; r++ {
+					ui, uj := u[r][i], u[r][j]
+					u[r][i] = c*ui - s*uj
+					u[r][j] = s*ui + c*uj
+				}
+				for r := 0; r < n; r++ {
+					vi, vj := v[r][i], v[r][j]
+					v[r][i] = c*vi - s*vj
+					v[r][j] = s*vi + c*vj
+				}
+			}
+		}
+		if !rotated {
+			break
+		}
+	}
+
+	//the singular values are the lengths of the columns
+	s := make([]float64, n)
+	for j := 0; j < n; j++ {
+		norm := 0.0
+		for r := 0; r < m; r++ {
+			norm = math.Hypot(norm, u[r][j])
+		}
+		s[j] = norm
+		for r := 0; r < m; r++ {
+			if norm != 0 {
+				u[r][j] /= norm
+			}
+		}
+	}
+
+	//sort into decreasing order
+	order := ColumnRange(0, n-1)
+	sort.SliceStable(order, func(a, b int) bool { return s[order[a]] > s[order[b]] })
+	result := &SVD{U: Zeros(m, n), S: make([]float64, n), V: Zeros(n, n)}
+	for k, j := range order {
+		result.S[k] = s[j]
+		for r := 0; r < m; r++ {
+			result.U[r][k] = u[r][j]
+		}
+		for r := 0; r < n; r++ {
+			result.V[r][k] = v[r][j]
+		}
+	}
+	return result, nil
+}
+
+// tolerance is the size below which a singular value is treated as zero.
+func (f *SVD) tolerance() float64 {
+	if len(f.S) == 0 {
+		return 0
+	}
+	size := math.Max(float64(len(f.U)), float64(len(f.V)))
+	return size * epsilon * f.S[0]
+}
+
+// Rank returns the number of singular values that are not zero.
+func (f *SVD) Rank() int {
+	result := 0
+	for _, v := range f.S {
+		if v > f.tolerance() {
+			result++
+		}
+	}
+	return result
+}
+
+// Cond returns the condition number of A in the 2-norm, the ratio of the
+// largest to the smallest singular value.  It is +Inf for a rank deficient
+// matrix, one with a singular value below the tolerance used by Rank.
+func (f *SVD) Cond() float64 {
+	smallest := f.S[len(f.S)-1]
+	if smallest <= f.tolerance() {
+		return math.Inf(1)
+	}
+	return f.S[0] / smallest
+}
+
+// PseudoInverse returns the n x m Moore-Penrose pseudo-inverse of A,
+// V diag(1/S) U^T, treating singular values below the tolerance as zero.
+func (f *SVD) PseudoInverse() [][]float64 {
+	m, n := len(f.U), len(f.V)
+	result := Zeros(n, m)
+	for k, s := range f.S {
+		if s <= f.tolerance() {
+			continue
+		}
+		for i := 0; i < n; i++ {
+			vk := f.V[i][k] / s
+			for j := 0; j < m; j++ {
+				result[i][j] += vk * f.U[j][k]
+			}
+		}
+	}
+	return result
+}
+
+// PseudoInverse returns the Moore-Penrose pseudo-inverse of A.
+func PseudoInverse(A [][]float64) ([][]float64, error) {
+	svd, err := NewSVD(A)
+	if err != nil {
+		return nil, err
+	}
+	return svd.PseudoInverse(), nil
+}
+
+// Cond returns the condition number of A in the 2-norm.
+func Cond(A [][]float64) (float64, error) {
+	svd, err := NewSVD(A)
+	if err != nil {
+		return 0, err
+	}
+	return svd.Cond(), nil
+}
+
+// Eigen is the eigendecomposition of a symmetric matrix A,
+// A = V diag(Values) V^T, with the eigenvalues in increasing order and the
+// matching eigenvectors in the columns of V.
+//
+// It is computed with cyclic Jacobi rotations.
+type Eigen struct {
+	Values []float64
+	V      [][]float64
+}
+
+// NewEigen decomposes the symmetric matrix A, which is not modified.
+func NewEigen(A [][]float64) (*Eigen, error) {
+	n := len(A)
+	if n == 0 || len(A[0]) != n {
+		return nil, fmt.Errorf("eigen: matrix is not square")
+	}
+	scale := 0.0
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			scale = math.Max(scale, math.Abs(A[i][j]))
+			if math.Abs(A[i][j]-A[j][i]) > 1e-10*math.Max(1, math.Abs(A[i][j])) {
+				return nil, fmt.Errorf("eigen: matrix is not symmetric")
+			}
+		}
+	}
+
+	a := Copy(A)
+	v := Identity(n)
+	for sweep := 0; sweep < 100; sweep++ {
+		off := 0.0
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				off += a[i][j] * a[i][j]
+			}
+		}
+		if math.Sqrt(off) <= epsilon*scale {
+			break
+		}
+
+		for p := 0; p < n-1; p++ {
+			for q := p + 1; q < n; q++ {
+				if a[p][q] == 0 {
+					continue
+				}
+				//rotate rows and columns p and q to zero out a[p][q]
+				theta := (a[q][q] - a[p][p]) / (2 * a[p][q])
+				t := math.Copysign(1, theta) / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+				for k := 0; k < n; k++ {
+					akp, akq := a[k][p], a[k][q]
+					a[k][p] = c*akp - s*akq
+					a[k][q] = s*akp + c*akq
+				}
+				for k := 0; k < n; k++ {
+					apk, aqk := a[p][k], a[q][k]
+					a[p][k] = c*apk - s*aqk
+					a[q][k] = s*apk + c*aqk
+				}
+				for k := 0; k < n; k++ {
+					vkp, vkq := v[k][p], v[k][q]
+					v[k][p] = c*vkp - s*vkq
+					v[k][q] = s*vkp + c*vkq
+				}
+			}
+		}
+	}
+
+	order := ColumnRange(0, n-1)
+	sort.SliceStable(order, func(x, y int) bool { return a[order[x]][order[x]] < a[order[y]][order[y]] })
+	result := &Eigen{Values: make([]float64, n), V: Zeros(n, n)}
+	for k, j := range order {
+		result.Values[k] = a[j][j]
+		for r := 0; r < n; r++ {
+			result.V[r][k] = v[r][j]
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,434 @@
+package ml
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// hilbert returns the n x n Hilbert matrix, a classic badly conditioned
+// matrix.
+func hilbert(n int) [][]float64 {
+	result := Zeros(n, n)
+	for i := range result {
+		for j := range result[i] {
+			result[i][j] = 1 / float64(i+j+1)
+		}
+	}
+	return result
+}
+
+// naiveMul returns A B with the textbook triple loop.
+func naiveMul(A [][]float64, B [][]float64) [][]float64 {
+	result := Zeros(len(A), len(B[0]))
+	for i := range A {
+		for j := range B[0] {
+			for k := range B {
+				result[i][j] += A[i][k] * B[k][j]
+			}
+		}
+	}
+	return result
+}
+
+// scaleColumns returns A with each column c multiplied by s[c].
+func scaleColumns(A [][]float64, s []float64) [][]float64 {
+	result := Copy(A)
+	for i := range result {
+		for c := range s {
+			result[i][c] *= s[c]
+		}
+	}
+	return result
+}
+
+// assertClose fails the test when got and want differ by more than tol in
+// any value.
+func assertClose(t *testing.T, name string, got [][]float64, want [][]float64, tol float64) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s: got %d rows, want %d", name, len(got), len(want))
+	}
+	for i := range want {
+		if len(got[i]) != len(want[i]) {
+			t.Fatalf("%s: row %d has %d columns, want %d", name, i, len(got[i]), len(want[i]))
+		}
+		for j := range want[i] {
+			if math.Abs(got[i][j]-want[i][j]) > tol {
+				t.Fatalf("%s: [%d][%d] = %v, want %v", name, i, j, got[i][j], want[i][j])
+			}
+		}
+	}
+}
+
+// assertErrorContains fails the test unless err holds text.
+func assertErrorContains(t *testing.T, err error, text string) {
+	t.Helper()
+	if err == nil {
+		t.Fatalf("got no error, want one containing %q", text)
+	}
+	if !strings.Contains(err.Error(), text) {
+		t.Fatalf("got error %q, want one containing %q", err, text)
+	}
+}
+
+var spd = [][]float64{
+	{4, 12, -16},
+	{12, 37, -43},
+	{-16, -43, 98},
+}
+
+var rankDeficient = [][]float64{
+	{1, 2, 3},
+	{2, 4, 6},
+	{1, 0, 1},
+	{3, 2, 5},
+}
+
+var tall = [][]float64{
+	{1, 2},
+	{3, 4},
+	{5, 6},
+	{7, 9},
+}
+
+var wide = [][]float64{
+	{1, 3, 5, 7},
+	{2, 4, 6, 9},
+}
+
+func TestQR(t *testing.T) {
+	tests := []struct {
+		name string
+		A    [][]float64
+	}{
+		{"hilbert", hilbert(5)},
+		{"spd", spd},
+		{"tall", tall},
+		{"rank deficient", rankDeficient},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			qr, err := NewQR(test.A)
+			if err != nil {
+				t.Fatal(err)
+			}
+			Q, R := qr.Q(), qr.R()
+			assertClose(t, "Q R", naiveMul(Q, R), test.A, 1e-12)
+			assertClose(t, "Q^T Q", naiveMul(Transpose(Q), Q), Identity(len(test.A[0])), 1e-12)
+			for i := range R {
+				for j := 0; j < i; j++ {
+					if R[i][j] != 0 {
+						t.Fatalf("R[%d][%d] = %v, want 0", i, j, R[i][j])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestQRSolve(t *testing.T) {
+	//a line through the points exactly, so the least squares weights are known
+	X := [][]float64{{1, 0}, {1, 1}, {1, 2}, {1, 3}}
+	T := [][]float64{{1, -1}, {3, -2}, {5, -3}, {7, -4}}
+	qr, err := NewQR(X)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := qr.Solve(T)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "w", w, [][]float64{{1, -1}, {2, -1}}, 1e-12)
+}
+
+func TestQRErrors(t *testing.T) {
+	_, err := NewQR(wide)
+	assertErrorContains(t, err, "fewer rows")
+
+	qr, err := NewQR(rankDeficient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if qr.IsFullRank() {
+		t.Fatal("IsFullRank() = true for a rank deficient matrix")
+	}
+	_, err = qr.Solve(Zeros(len(rankDeficient), 1))
+	assertErrorContains(t, err, "rank deficient")
+
+	qr, err = NewQR(tall)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = qr.Solve(Zeros(2, 1))
+	assertErrorContains(t, err, "right hand side")
+}
+
+func TestCholesky(t *testing.T) {
+	tests := []struct {
+		name string
+		A    [][]float64
+		L    [][]float64
+	}{
+		{"spd", spd, [][]float64{{2, 0, 0}, {6, 1, 0}, {-8, 5, 3}}},
+		{"hilbert", hilbert(5), nil},
+		{"identity", Identity(3), Identity(3)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ch, err := NewCholesky(test.A)
+			if err != nil {
+				t.Fatal(err)
+			}
+			L := ch.L()
+			assertClose(t, "L L^T", naiveMul(L, Transpose(L)), test.A, 1e-12)
+			if test.L != nil {
+				assertClose(t, "L", L, test.L, 1e-12)
+			}
+
+			//solve for a known X
+			X := [][]float64{{1}, {-2}, {3}}
+			if len(test.A) != len(X) {
+				return
+			}
+			got, err := ch.Solve(naiveMul(test.A, X))
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertClose(t, "Solve", got, X, 1e-9)
+		})
+	}
+}
+
+func TestCholeskyErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		A    [][]float64
+		text string
+	}{
+		{"indefinite", [][]float64{{1, 2}, {2, 1}}, "not positive definite"},
+		{"singular", [][]float64{{1, 1}, {1, 1}}, "not positive definite"},
+		{"negative", [][]float64{{-4}}, "not positive definite"},
+		{"not square", tall, "not square"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewCholesky(test.A)
+			assertErrorContains(t, err, test.text)
+		})
+	}
+}
+
+func TestLU(t *testing.T) {
+	tests := []struct {
+		name string
+		A    [][]float64
+		det  float64
+	}{
+		{"spd", spd, 36},
+		{"needs pivoting", [][]float64{{0, 1}, {1, 0}}, -1},
+		{"general", [][]float64{{2, 1, 1}, {4, -6, 0}, {-2, 7, 2}}, -16},
+		{"hilbert", hilbert(4), 1.0 / 6048000},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lu, err := NewLU(test.A)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if lu.IsSingular() {
+				t.Fatal("IsSingular() = true")
+			}
+			PA := Rows(test.A, lu.Pivot())
+			assertClose(t, "P A = L U", naiveMul(lu.L(), lu.U()), PA, 1e-12)
+			if got := lu.Det(); math.Abs(got-test.det) > 1e-9*math.Max(1, math.Abs(test.det)) {
+				t.Fatalf("Det() = %v, want %v", got, test.det)
+			}
+			inverse, err := lu.Inverse()
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertClose(t, "A A^-1", naiveMul(test.A, inverse), Identity(len(test.A)), 1e-9)
+		})
+	}
+}
+
+func TestLUErrors(t *testing.T) {
+	_, err := NewLU(tall)
+	assertErrorContains(t, err, "not square")
+
+	singular := [][]float64{{1, 2, 3}, {2, 4, 6}, {1, 1, 1}}
+	lu, err := NewLU(singular)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !lu.IsSingular() {
+		t.Fatal("IsSingular() = false for a singular matrix")
+	}
+	if det := lu.Det(); det != 0 {
+		t.Fatalf("Det() = %v, want 0", det)
+	}
+	_, err = lu.Solve([][]float64{{1}, {2}, {3}})
+	assertErrorContains(t, err, "singular")
+	_, err = lu.Inverse()
+	assertErrorContains(t, err, "singular")
+
+	//rounding leaves a tiny value instead of an exact zero in U
+	rounded, err := NewLU([][]float64{{1, 2, 3}, {4, 5, 6}, {7, 8, 9}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u := rounded.U(); u[2][2] == 0 {
+		t.Fatal("U has an exact zero, the matrix doesn't test the tolerance")
+	}
+	if !rounded.IsSingular() {
+		t.Fatal("IsSingular() = false for a singular matrix with rounding")
+	}
+	_, err = rounded.Solve([][]float64{{1}, {2}, {3}})
+	assertErrorContains(t, err, "singular")
+
+	//badly conditioned is not singular
+	nearly, err := NewLU(hilbert(6))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nearly.IsSingular() {
+		t.Fatal("IsSingular() = true for hilbert(6)")
+	}
+}
+
+func TestSVD(t *testing.T) {
+	tests := []struct {
+		name string
+		A    [][]float64
+		rank int
+	}{
+		{"hilbert", hilbert(5), 5},
+		{"spd", spd, 3},
+		{"tall", tall, 2},
+		{"wide", wide, 2},
+		{"rank deficient", rankDeficient, 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			svd, err := NewSVD(test.A)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertClose(t, "U diag(S) V^T", naiveMul(scaleColumns(svd.U, svd.S), Transpose(svd.V)), test.A, 1e-12)
+			assertClose(t, "V^T V", naiveMul(Transpose(svd.V), svd.V), Identity(len(svd.S)), 1e-12)
+			for k := 1; k < len(svd.S); k++ {
+				if svd.S[k] > svd.S[k-1] {
+					t.Fatalf("S = %v is not in decreasing order", svd.S)
+				}
+			}
+			if got := svd.Rank(); got != test.rank {
+				t.Fatalf("Rank() = %v, want %v", got, test.rank)
+			}
+		})
+	}
+}
+
+func TestPseudoInverse(t *testing.T) {
+	tests := []struct {
+		name string
+		A    [][]float64
+	}{
+		{"spd", spd},
+		{"tall", tall},
+		{"wide", wide},
+		{"rank deficient", rankDeficient},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pinv, err := PseudoInverse(test.A)
+			if err != nil {
+				t.Fatal(err)
+			}
+			//the Moore-Penrose conditions A A+ A = A and A+ A A+ = A+
+			assertClose(t, "A A+ A", naiveMul(naiveMul(test.A, pinv), test.A), test.A, 1e-10)
+			assertClose(t, "A+ A A+", naiveMul(naiveMul(pinv, test.A), pinv), pinv, 1e-10)
+		})
+	}
+
+	//the pseudo-inverse of an invertible matrix is its inverse
+	pinv, err := PseudoInverse(spd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "A+ A", naiveMul(pinv, spd), Identity(3), 1e-10)
+}
+
+func TestCond(t *testing.T) {
+	tests := []struct {
+		name string
+		A    [][]float64
+		want float64
+		tol  float64
+	}{
+		{"identity", Identity(4), 1, 1e-12},
+		{"diagonal", [][]float64{{10, 0}, {0, 0.5}}, 20, 1e-12},
+		{"hilbert", hilbert(4), 15513.738873929, 1e-6},
+		{"singular", [][]float64{{1, 1}, {1, 1}}, math.Inf(1), 0},
+		//the smallest singular value is not an exact zero after rounding
+		{"singular with rounding", [][]float64{{1, 2, 3}, {4, 5, 6}, {7, 8, 9}}, math.Inf(1), 0},
+		{"rank deficient", rankDeficient, math.Inf(1), 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Cond(test.A)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.IsInf(test.want, 1) {
+				if !math.IsInf(got, 1) {
+					t.Fatalf("Cond() = %v, want +Inf", got)
+				}
+				return
+			}
+			if math.Abs(got-test.want) > test.tol*test.want {
+				t.Fatalf("Cond() = %v, want %v", got, test.want)
+			}
+		})
+	}
+
+	_, err := Cond(nil)
+	assertErrorContains(t, err, "empty")
+}
+
+func TestEigen(t *testing.T) {
+	tests := []struct {
+		name   string
+		A      [][]float64
+		values []float64
+	}{
+		{"diagonal", [][]float64{{3, 0}, {0, 1}}, []float64{1, 3}},
+		{"symmetric", [][]float64{{2, 1}, {1, 2}}, []float64{1, 3}},
+		{"spd", spd, nil},
+		{"hilbert", hilbert(5), nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			eigen, err := NewEigen(test.A)
+			if err != nil {
+				t.Fatal(err)
+			}
+			V := eigen.V
+			assertClose(t, "V diag(values) V^T", naiveMul(scaleColumns(V, eigen.Values), Transpose(V)), test.A, 1e-12)
+			assertClose(t, "V^T V", naiveMul(Transpose(V), V), Identity(len(test.A)), 1e-12)
+			for k := 1; k < len(eigen.Values); k++ {
+				if eigen.Values[k] < eigen.Values[k-1] {
+					t.Fatalf("Values = %v is not in increasing order", eigen.Values)
+				}
+			}
+			if test.values != nil {
+				assertClose(t, "Values", [][]float64{eigen.Values}, [][]float64{test.values}, 1e-12)
+			}
+		})
+	}
+
+	_, err := NewEigen([][]float64{{1, 2}, {3, 4}})
+	assertErrorContains(t, err, "not symmetric")
+	_, err = NewEigen(tall)
+	assertErrorContains(t, err, "not square")
+}
@@ -0,0 +1,186 @@
+package ml
+
+import (
+	"fmt"
+)
+
+// Dense is a matrix stored row after row in one flat slice.  Unlike
+// [][]float64 a Dense can be sliced into views that share its memory, and its
+// operations write into a destination matrix, so a training loop can reuse
+// the same buffers on every step instead of allocating new ones.
+type Dense struct {
+	rows, cols int
+	//stride is the distance in data between the start of two rows, it is
+	//larger than cols for views of a wider matrix.
+	stride int
+	data   []float64
+}
+
+// NewDense returns a rows x cols matrix using data as its storage, row after
+// row.  When data is nil a matrix of zeros is allocated.
+func NewDense(rows int, cols int, data []float64) *Dense {
+	if data == nil {
+		data = make([]float64, rows*cols)
+	}
+	if len(data) != rows*cols {
+		panic(fmt.Sprintf("ml: %d values for a %d x %d matrix", len(data), rows, cols))
+	}
+	return &Dense{rows: rows, cols: cols, stride: cols, data: data}
+}
+
+// DenseOf copies a [][]float64 matrix into a new Dense.
+func DenseOf(m [][]float64) *Dense {
+	if len(m) == 0 {
+		return NewDense(0, 0, nil)
+	}
+	result := NewDense(len(m), len(m[0]), nil)
+	for r := range m {
+		copy(result.Row(r), m[r])
+	}
+	return result
+}
+
+// Slices copies the matrix into a new [][]float64.
+func (d *Dense) Slices() [][]float64 {
+	result := make([][]float64, d.rows)
+	for r := range result {
+		result[r] = append([]float64(nil), d.Row(r)...)
+	}
+	return result
+}
+
+// Dims returns the number of rows and columns.
+func (d *Dense) Dims() (int, int) {
+	return d.rows, d.cols
+}
+
+// At returns the value at row i, column j.
+func (d *Dense) At(i int, j int) float64 {
+	return d.data[i*d.stride+j]
+}
+
+// Set sets the value at row i, column j.
+func (d *Dense) Set(i int, j int, v float64) {
+	d.data[i*d.stride+j] = v
+}
+
+// Row returns row i, sharing memory with the matrix.
+func (d *Dense) Row(i int) []float64 {
+	start := i * d.stride
+	return d.data[start : start+d.cols : start+d.cols]
+}
+
+// Slice returns a view of rows i0 through i1-1 and columns j0 through j1-1
+// that shares memory with the matrix.
+func (d *Dense) Slice(i0 int, i1 int, j0 int, j1 int) *Dense {
+	if i0 < 0 || i1 > d.rows || i0 > i1 || j0 < 0 || j1 > d.cols || j0 > j1 {
+		panic(fmt.Sprintf("ml: slice [%d:%d, %d:%d] out of range of %d x %d matrix", i0, i1, j0, j1, d.rows, d.cols))
+	}
+	result := &Dense{rows: i1 - i0, cols: j1 - j0, stride: d.stride}
+	if result.rows > 0 && result.cols > 0 {
+		start := i0*d.stride + j0
+		result.data = d.data[start : start+(result.rows-1)*d.stride+result.cols]
+	}
+	return result
+}
+
+// Copy copies a into d, which must have the same shape.
+func (d *Dense) Copy(a *Dense) {
+	d.checkShape(a.rows, a.cols)
+	for r := 0; r < d.rows; r++ {
+		copy(d.Row(r), a.Row(r))
+	}
+}
+
+// Zero sets every value to zero.
+func (d *Dense) Zero() {
+	for r := 0; r < d.rows; r++ {
+		row := d.Row(r)
+		for c := range row {
+			row[c] = 0
+		}
+	}
+}
+
+// Mul sets d to the matrix product a b using the default Multiplier.  d must
+// not share memory with a or b.
+func (d *Dense) Mul(a *Dense, b *Dense) {
+	Multiplier{}.Mul(d, a, b)
+}
+
+// MulTransA sets d to a^T b without forming the transpose of a.  d must not
+// share memory with a or b.
+func (d *Dense) MulTransA(a *Dense, b *Dense) {
+	if a.rows != b.rows {
+		panic(fmt.Sprintf("ml: can't multiply (%d x %d)^T by %d x %d", a.rows, a.cols, b.rows, b.cols))
+	}
+	d.checkShape(a.cols, b.cols)
+	d.Zero()
+	for k := 0; k < a.rows; k++ {
+		aRow := a.Row(k)
+		bRow := b.Row(k)
+		for r, v := range aRow {
+			dRow := d.Row(r)
+			for c, w := range bRow {
+				dRow[c] += v * w
+			}
+		}
+	}
+}
+
+// Add sets d to a + b.
+func (d *Dense) Add(a *Dense, b *Dense) {
+	d.apply(a, b, func(x, y float64) float64 { return x + y })
+}
+
+// Sub sets d to a - b.
+func (d *Dense) Sub(a *Dense, b *Dense) {
+	d.apply(a, b, func(x, y float64) float64 { return x - y })
+}
+
+// MulElem sets d to the element by element product of a and b.
+func (d *Dense) MulElem(a *Dense, b *Dense) {
+	d.apply(a, b, func(x, y float64) float64 { return x * y })
+}
+
+// Scale sets d to s a.
+func (d *Dense) Scale(s float64, a *Dense) {
+	d.apply(a, a, func(x, y float64) float64 { return x * s })
+}
+
+// AddScaled sets d to a + s b.
+func (d *Dense) AddScaled(a *Dense, s float64, b *Dense) {
+	d.apply(a, b, func(x, y float64) float64 { return x + y*s })
+}
+
+// T returns a new matrix holding the transpose of d.
+func (d *Dense) T() *Dense {
+	result := NewDense(d.cols, d.rows, nil)
+	for r := 0; r < d.rows; r++ {
+		for c, v := range d.Row(r) {
+			result.data[c*result.stride+r] = v
+		}
+	}
+	return result
+}
+
+// apply sets every value of d to f of the matching values of a and b, which
+// may share memory with d.
+func (d *Dense) apply(a *Dense, b *Dense, f func(x, y float64) float64) {
+	if a.rows != b.rows || a.cols != b.cols {
+		panic(fmt.Sprintf("ml: shapes %d x %d and %d x %d don't match", a.rows, a.cols, b.rows, b.cols))
+	}
+	d.checkShape(a.rows, a.cols)
+	for r := 0; r < d.rows; r++ {
+		aRow, bRow, dRow := a.Row(r), b.Row(r), d.Row(r)
+		for c := range dRow {
+			dRow[c] = f(aRow[c], bRow[c])
+		}
+	}
+}
+
+func (d *Dense) checkShape(rows int, cols int) {
+	if d.rows != rows || d.cols != cols {
+		panic(fmt.Sprintf("ml: destination is %d x %d, expected %d x %d", d.rows, d.cols, rows, cols))
+	}
+}
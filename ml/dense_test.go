@@ -0,0 +1,137 @@
+package ml
+
+import (
+	"strings"
+	"testing"
+)
+
+// assertPanics checks that f panics with a message containing text.
+func assertPanics(t *testing.T, text string, f func()) {
+	t.Helper()
+	defer func() {
+		t.Helper()
+		r := recover()
+		if r == nil {
+			t.Fatalf("no panic, want one containing %q", text)
+		}
+		if msg, ok := r.(string); !ok || !strings.Contains(msg, text) {
+			t.Fatalf("panic %v, want one containing %q", r, text)
+		}
+	}()
+	f()
+}
+
+func TestNewDense(t *testing.T) {
+	d := NewDense(2, 3, nil)
+	if rows, cols := d.Dims(); rows != 2 || cols != 3 {
+		t.Fatalf("Dims() = %d, %d, want 2, 3", rows, cols)
+	}
+	assertClose(t, "zeros", d.Slices(), Zeros(2, 3), 0)
+
+	//the data is used row after row and shared with the matrix
+	data := []float64{1, 2, 3, 4, 5, 6}
+	d = NewDense(3, 2, data)
+	assertClose(t, "rows", d.Slices(), [][]float64{{1, 2}, {3, 4}, {5, 6}}, 0)
+	data[3] = 40
+	if d.At(1, 1) != 40 {
+		t.Fatalf("At(1, 1) = %v, want 40 after changing data", d.At(1, 1))
+	}
+
+	assertPanics(t, "5 values for a 2 x 3 matrix", func() { NewDense(2, 3, make([]float64, 5)) })
+}
+
+func TestDenseOf(t *testing.T) {
+	m := [][]float64{{1, 2, 3}, {4, 5, 6}}
+	d := DenseOf(m)
+	assertClose(t, "Slices", d.Slices(), m, 0)
+
+	//both directions copy
+	m[0][0] = 10
+	if d.At(0, 0) != 1 {
+		t.Fatalf("At(0, 0) = %v, DenseOf didn't copy", d.At(0, 0))
+	}
+	s := d.Slices()
+	s[1][2] = 60
+	if d.At(1, 2) != 6 {
+		t.Fatalf("At(1, 2) = %v, Slices didn't copy", d.At(1, 2))
+	}
+
+	if rows, cols := DenseOf(nil).Dims(); rows != 0 || cols != 0 {
+		t.Fatalf("DenseOf(nil) is %d x %d", rows, cols)
+	}
+}
+
+func TestDenseAtSet(t *testing.T) {
+	d := NewDense(2, 3, nil)
+	d.Set(0, 2, 7)
+	d.Set(1, 0, -1)
+	if d.At(0, 2) != 7 || d.At(1, 0) != -1 {
+		t.Fatalf("At after Set = %v, %v, want 7, -1", d.At(0, 2), d.At(1, 0))
+	}
+	assertClose(t, "Slices", d.Slices(), [][]float64{{0, 0, 7}, {-1, 0, 0}}, 0)
+
+	//a view shares memory and is indexed from its own corner
+	view := d.Slice(1, 2, 1, 3)
+	view.Set(0, 1, 9)
+	if d.At(1, 2) != 9 {
+		t.Fatalf("At(1, 2) = %v, want 9 set through the view", d.At(1, 2))
+	}
+	assertClose(t, "view", view.Slices(), [][]float64{{0, 9}}, 0)
+
+	assertPanics(t, "out of range of 2 x 3 matrix", func() { d.Slice(0, 3, 0, 1) })
+}
+
+func TestDenseT(t *testing.T) {
+	d := DenseOf([][]float64{{1, 2, 3}, {4, 5, 6}})
+	assertClose(t, "T", d.T().Slices(), [][]float64{{1, 4}, {2, 5}, {3, 6}}, 0)
+
+	//the transpose of a view only holds the view
+	view := d.Slice(0, 2, 1, 3)
+	assertClose(t, "view T", view.T().Slices(), [][]float64{{2, 5}, {3, 6}}, 0)
+}
+
+func TestDenseMul(t *testing.T) {
+	a := [][]float64{{1, 2}, {3, 4}, {5, 6}}
+	b := [][]float64{{1, 0, -1}, {2, 1, 0}}
+	d := NewDense(3, 3, nil)
+	d.Mul(DenseOf(a), DenseOf(b))
+	assertClose(t, "Mul", d.Slices(), naiveMul(a, b), 0)
+
+	//d is overwritten, not added to
+	d.Mul(DenseOf(a), DenseOf(b))
+	assertClose(t, "Mul again", d.Slices(), naiveMul(a, b), 0)
+
+	c := [][]float64{{1}, {-1}, {2}}
+	at := NewDense(2, 1, nil)
+	at.MulTransA(DenseOf(a), DenseOf(c))
+	assertClose(t, "MulTransA", at.Slices(), naiveMul(DenseOf(a).T().Slices(), c), 0)
+}
+
+func TestDenseElementwise(t *testing.T) {
+	a := DenseOf([][]float64{{1, 2}, {3, 4}})
+	b := DenseOf([][]float64{{10, 20}, {30, 40}})
+	d := NewDense(2, 2, nil)
+
+	d.Add(a, b)
+	assertClose(t, "Add", d.Slices(), [][]float64{{11, 22}, {33, 44}}, 0)
+	d.Sub(b, a)
+	assertClose(t, "Sub", d.Slices(), [][]float64{{9, 18}, {27, 36}}, 0)
+	d.MulElem(a, b)
+	assertClose(t, "MulElem", d.Slices(), [][]float64{{10, 40}, {90, 160}}, 0)
+	d.Scale(0.5, a)
+	assertClose(t, "Scale", d.Slices(), [][]float64{{0.5, 1}, {1.5, 2}}, 0)
+	//the destination may be one of the operands
+	d.AddScaled(d, 2, a)
+	assertClose(t, "AddScaled", d.Slices(), [][]float64{{2.5, 5}, {7.5, 10}}, 0)
+}
+
+func TestDenseShapeMismatch(t *testing.T) {
+	a := NewDense(2, 3, nil)
+	b := NewDense(3, 2, nil)
+	assertPanics(t, "can't multiply 2 x 3 by 2 x 3", func() { NewDense(2, 3, nil).Mul(a, a) })
+	assertPanics(t, "destination is 2 x 3, expected 2 x 2", func() { NewDense(2, 3, nil).Mul(a, b) })
+	assertPanics(t, "can't multiply (2 x 3)^T by 3 x 2", func() { NewDense(3, 2, nil).MulTransA(a, b) })
+	assertPanics(t, "shapes 2 x 3 and 3 x 2 don't match", func() { NewDense(2, 3, nil).Add(a, b) })
+	assertPanics(t, "destination is 3 x 2, expected 2 x 3", func() { NewDense(3, 2, nil).Sub(a, a) })
+	assertPanics(t, "destination is 2 x 2, expected 2 x 3", func() { NewDense(2, 2, nil).Copy(a) })
+}
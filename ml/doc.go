@@ -0,0 +1,19 @@
+// Package ml holds the pieces of the tutorial modules that are shared between
+// them: loading the data, splitting it into training and test sets, scaling,
+// training linear models and measuring how well they did.
+//
+// Most functions take and return matrices the same way the tutorial modules
+// store them, as a slice of rows ([][]float64), so the output of any function
+// can be logged with klog and compared against the module READMEs.  That is
+// the place to start.
+//
+// Three other matrix types are there for when speed or memory matters.
+// Dense keeps a matrix row after row in one slice and writes the results of
+// its operations into matrices that can be reused, so loops such as
+// SGD.TrainDense don't allocate on every step.  Use DenseOf and Slices to move
+// between the two forms.  CSR and CSC keep only the non-zero values, by rows
+// and by columns, for data that is mostly zeros such as one hot encoded
+// categories or files in the libsvm format.  CSR is the one to train on with
+// SGD.TrainCSR, as a batch is a set of rows, and CSC is its transpose, used
+// for walking down the columns.
+package ml
@@ -0,0 +1,158 @@
+package ml
+
+import (
+	"math"
+)
+
+// EarlyStopping stops SGD once the error on validation data stops improving,
+// and puts back the weights from the epoch with the lowest error.  The
+// validation data is SGD.ValidationX and ValidationT when they are set, or
+// else a Fraction of the training rows held out with SGD.Seed.
+//
+// After training BestEpoch, BestScore and StoppedEpoch tell how it went.
+type EarlyStopping struct {
+	//Fraction is the fraction of the training rows to hold out, it defaults
+	//to 0.2.
+	Fraction float64
+	//Metric measures the error of each output column, such as RMSE, MAE or
+	//MSE, and the mean over the columns is monitored.  It defaults to RMSE.
+	Metric func(predicted [][]float64, T [][]float64) []float64
+	//Patience is the number of epochs without improvement to wait before
+	//stopping.
+	Patience int
+	//MinDelta is how much the error has to drop by to count as an
+	//improvement.
+	MinDelta float64
+
+	//BestEpoch is the epoch the restored weights came from and BestScore
+	//their error.
+	BestEpoch int
+	BestScore float64
+	//StoppedEpoch is the last epoch that was run, it is less than
+	//SGD.Epochs-1 when training stopped early.
+	StoppedEpoch int
+
+	best *Dense
+	wait int
+}
+
+// heldOut is the validation data of a training run.
+type heldOut struct {
+	predict func(w [][]float64) [][]float64
+	T       [][]float64
+}
+
+// holdOut returns the training and validation rows when early stopping has
+// to carve its validation data out of the training data.
+func (s SGD) holdOut(samples int) ([]int, []int, bool) {
+	if s.EarlyStopping == nil || s.ValidationX != nil {
+		return nil, nil, false
+	}
+	fraction := s.EarlyStopping.Fraction
+	if fraction <= 0 || fraction >= 1 {
+		fraction = 0.2
+	}
+	split, err := Splitter{Train: 1 - fraction, Seed: s.Seed, Rand: s.Rand}.Split(samples)
+	if err != nil || len(split.Train) == 0 || len(split.Test) == 0 {
+		return nil, nil, false
+	}
+	return split.Train, split.Test, true
+}
+
+// holdOutDense returns the rows of X and T to train on and the validation
+// data, if there is any.
+func (s SGD) holdOutDense(X *Dense, T *Dense) (*Dense, *Dense, *heldOut) {
+	samples, _ := X.Dims()
+	if train, validation, ok := s.holdOut(samples); ok {
+		validationX := denseRows(X, validation).Slices()
+		return denseRows(X, train), denseRows(T, train), &heldOut{
+			predict: func(w [][]float64) [][]float64 { return Predict(validationX, w) },
+			T:       denseRows(T, validation).Slices(),
+		}
+	}
+	return X, T, s.validation()
+}
+
+// holdOutCSR returns the rows of X and T to train on and the validation
+// data, if there is any.
+func (s SGD) holdOutCSR(X *CSR, T *Dense) (*CSR, *Dense, *heldOut) {
+	samples, _ := X.Dims()
+	if train, validation, ok := s.holdOut(samples); ok {
+		validationX := X.Rows(validation)
+		return X.Rows(train), denseRows(T, train), &heldOut{
+			predict: func(w [][]float64) [][]float64 { return PredictCSR(validationX, w) },
+			T:       denseRows(T, validation).Slices(),
+		}
+	}
+	return X, T, s.validation()
+}
+
+// validation returns ValidationX and ValidationT, if they are set.
+func (s SGD) validation() *heldOut {
+	if s.ValidationX == nil {
+		return nil
+	}
+	return &heldOut{
+		predict: func(w [][]float64) [][]float64 { return Predict(s.ValidationX, w) },
+		T:       s.ValidationT,
+	}
+}
+
+// denseRows returns a new matrix holding the rows of d at indexes.
+func denseRows(d *Dense, indexes []int) *Dense {
+	result := NewDense(len(indexes), d.cols, nil)
+	for i, r := range indexes {
+		copy(result.Row(i), d.Row(r))
+	}
+	return result
+}
+
+// start gets ready for a new training run, or for resuming one from the
+// state saved in a checkpoint when resumed is not nil.
+func (e *EarlyStopping) start(w *Dense, resumed *EarlyStoppingState) {
+	e.best = NewDense(w.rows, w.cols, nil)
+	e.best.Copy(w)
+	e.BestEpoch, e.BestScore, e.StoppedEpoch = -1, math.Inf(1), -1
+	e.wait = 0
+	if resumed != nil && resumed.BestEpoch >= 0 {
+		e.best.Copy(DenseOf(resumed.W))
+		e.BestEpoch, e.BestScore = resumed.BestEpoch, resumed.BestScore
+		e.wait = resumed.Wait
+	}
+}
+
+// state returns a copy of what has been learned so far.  The best score is
+// only kept once an epoch has been measured, as JSON has no infinity.
+func (e *EarlyStopping) state() *EarlyStoppingState {
+	result := &EarlyStoppingState{BestEpoch: e.BestEpoch, Wait: e.wait, W: e.best.Slices()}
+	if e.BestEpoch >= 0 {
+		result.BestScore = e.BestScore
+	}
+	return result
+}
+
+// check records the validation error after an epoch and returns true when
+// it is time to stop.
+func (e *EarlyStopping) check(epoch int, predicted [][]float64, T [][]float64, w *Dense) bool {
+	metric := e.Metric
+	if metric == nil {
+		metric = RMSE
+	}
+	score := mean(metric(predicted, T))
+	e.StoppedEpoch = epoch
+	if score < e.BestScore-e.MinDelta {
+		e.BestEpoch, e.BestScore = epoch, score
+		e.best.Copy(w)
+		e.wait = 0
+		return false
+	}
+	e.wait++
+	return e.wait >= e.Patience
+}
+
+// restore puts back the best weights.
+func (e *EarlyStopping) restore(w *Dense) {
+	if e.BestEpoch >= 0 {
+		w.Copy(e.best)
+	}
+}
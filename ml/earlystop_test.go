@@ -0,0 +1,72 @@
+package ml
+
+import (
+	"testing"
+)
+
+func TestEarlyStoppingRestoresBest(t *testing.T) {
+	X, T := line()
+	//the validation targets are half the size, so the validation error falls
+	//while the weights grow toward them and then rises as they grow past
+	validationT := make([][]float64, len(T))
+	for i := range T {
+		validationT[i] = []float64{T[i][0] / 2}
+	}
+	tests := []struct {
+		name     string
+		patience int
+		stopped  bool
+	}{
+		{"stops", 3, true},
+		{"runs out of epochs", 100, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stopping := &EarlyStopping{Patience: test.patience}
+			var validation []float64
+			var weights [][][]float64
+			s := SGD{
+				LearningRate:  0.003,
+				Epochs:        30,
+				NoShuffle:     true,
+				ValidationX:   X,
+				ValidationT:   validationT,
+				EarlyStopping: stopping,
+				OnReport:      func(r EpochReport) { validation = append(validation, r.ValidationRMSE[0]) },
+				OnCheckpoint:  func(c *Checkpoint) { weights = append(weights, c.W) },
+			}
+			w, err := s.Train(X, T, Zeros(3, 1))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			best := 0
+			for epoch, rmse := range validation {
+				if rmse < validation[best] {
+					best = epoch
+				}
+			}
+			if best == 0 || best == len(validation)-1 {
+				t.Fatalf("validation errors %v don't fall and then rise", validation)
+			}
+			if stopping.BestEpoch != best || stopping.BestScore != validation[best] {
+				t.Fatalf("BestEpoch, BestScore = %d, %v, want %d, %v", stopping.BestEpoch, stopping.BestScore, best, validation[best])
+			}
+			wantStopped := s.Epochs - 1
+			if test.stopped {
+				wantStopped = best + test.patience
+				if wantStopped >= s.Epochs-1 {
+					t.Fatalf("best epoch %d is too late to stop early", best)
+				}
+			}
+			if stopping.StoppedEpoch != wantStopped || len(validation) != wantStopped+1 {
+				t.Fatalf("StoppedEpoch = %d after %d epochs, want %d", stopping.StoppedEpoch, len(validation), wantStopped)
+			}
+			//the weights of the best epoch are put back, not those of the last
+			assertClose(t, "w", w, weights[best], 0)
+			if validation[len(validation)-1] <= validation[best] {
+				t.Fatalf("last validation error %v is not above the best %v", validation[len(validation)-1], validation[best])
+			}
+		})
+	}
+}
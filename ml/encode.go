@@ -0,0 +1,275 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// CategoryEncoder turns a categorical (text) column into numeric columns.  Like
+// the other preprocessing steps it is fit on the training data only.
+type CategoryEncoder interface {
+	//Fit learns the categories of values.  t holds the target of every row
+	//and is only used by encoders that need it.
+	Fit(values []string, t []float64) error
+	//Transform returns the encoded columns for values.
+	Transform(values []string) [][]float64
+	//Names returns the names of the encoded columns of the named column.
+	Names(column string) []string
+}
+
+// OneHotEncoder encodes a category as one column per known category holding
+// 1 for the row's category and 0 for the others.  Categories that were not
+// seen during Fit are encoded as all 0's.
+type OneHotEncoder struct {
+	//Categories is the fitted vocabulary, it is kept as is by Fit when it is
+	//already set.
+	Categories []string `json:"categories"`
+	//DropFirst leaves out the column of the first category, which otherwise
+	//always adds up to the bias column with the others.
+	DropFirst bool `json:"dropFirst,omitempty"`
+}
+
+// Fit learns the sorted vocabulary of values.
+func (e *OneHotEncoder) Fit(values []string, t []float64) error {
+	if len(e.Categories) == 0 {
+		e.Categories = uniqueSorted(values)
+	}
+	if len(e.Categories) == 0 {
+		return fmt.Errorf("one-hot encoder: no categories to fit")
+	}
+	return nil
+}
+
+// Transform returns one row of indicator columns for every value.
+func (e *OneHotEncoder) Transform(values []string) [][]float64 {
+	index := categoryIndex(e.Categories)
+	first := 0
+	if e.DropFirst {
+		first = 1
+	}
+	result := Zeros(len(values), len(e.Categories)-first)
+	for r, v := range values {
+		if i, ok := index[v]; ok && i >= first {
+			result[r][i-first] = 1.0
+		}
+	}
+	return result
+}
+
+// Names returns "column=category" for every encoded column.
+func (e *OneHotEncoder) Names(column string) []string {
+	var result []string
+	for i, v := range e.Categories {
+		if i == 0 && e.DropFirst {
+			continue
+		}
+		result = append(result, column+"="+v)
+	}
+	return result
+}
+
+// OrdinalEncoder encodes a category as its position in Categories.  Categories
+// that were not seen during Fit are encoded as a missing value (NaN).
+type OrdinalEncoder struct {
+	//Categories is the fitted vocabulary in order, it is kept as is by Fit when
+	//it is already set so an order such as low, medium, high can be given.
+	Categories []string `json:"categories"`
+}
+
+// Fit learns the sorted vocabulary of values.
+func (e *OrdinalEncoder) Fit(values []string, t []float64) error {
+	if len(e.Categories) == 0 {
+		e.Categories = uniqueSorted(values)
+	}
+	if len(e.Categories) == 0 {
+		return fmt.Errorf("ordinal encoder: no categories to fit")
+	}
+	return nil
+}
+
+// Transform returns a single column with the position of every value.
+func (e *OrdinalEncoder) Transform(values []string) [][]float64 {
+	index := categoryIndex(e.Categories)
+	result := Zeros(len(values), 1)
+	for r, v := range values {
+		if i, ok := index[v]; ok {
+			result[r][0] = float64(i)
+		} else {
+			result[r][0] = math.NaN()
+		}
+	}
+	return result
+}
+
+// Names returns the name of the column.
+func (e *OrdinalEncoder) Names(column string) []string {
+	return []string{column}
+}
+
+// TargetEncoder encodes a category as the mean target value of the training
+// rows in that category.  The means are pulled towards the overall mean
+// (Prior) by Smoothing, which acts as that many extra rows holding the
+// overall mean, so rare categories don't get extreme values.  Categories that
+// were not seen during Fit are encoded as Prior.
+type TargetEncoder struct {
+	Smoothing float64 `json:"smoothing"`
+
+	Categories []string  `json:"categories"`
+	Means      []float64 `json:"means"`
+	Prior      float64   `json:"prior"`
+}
+
+// Fit finds the mean target of every category of values.
+func (e *TargetEncoder) Fit(values []string, t []float64) error {
+	if len(values) == 0 {
+		return fmt.Errorf("target encoder: no categories to fit")
+	}
+	if len(t) != len(values) {
+		return fmt.Errorf("target encoder: %d targets for %d values", len(t), len(values))
+	}
+
+	e.Prior = 0.0
+	for _, v := range t {
+		e.Prior += v
+	}
+	e.Prior = e.Prior / float64(len(t))
+
+	e.Categories = uniqueSorted(values)
+	index := categoryIndex(e.Categories)
+	sums := make([]float64, len(e.Categories))
+	counts := make([]float64, len(e.Categories))
+	for r, v := range values {
+		sums[index[v]] += t[r]
+		counts[index[v]]++
+	}
+	e.Means = make([]float64, len(e.Categories))
+	for i := range e.Means {
+		e.Means[i] = (sums[i] + e.Smoothing*e.Prior) / (counts[i] + e.Smoothing)
+	}
+	return nil
+}
+
+// Transform returns a single column with the target mean of every value.
+func (e *TargetEncoder) Transform(values []string) [][]float64 {
+	index := categoryIndex(e.Categories)
+	result := Zeros(len(values), 1)
+	for r, v := range values {
+		if i, ok := index[v]; ok {
+			result[r][0] = e.Means[i]
+		} else {
+			result[r][0] = e.Prior
+		}
+	}
+	return result
+}
+
+// Names returns the name of the column.
+func (e *TargetEncoder) Names(column string) []string {
+	return []string{column}
+}
+
+// EncodedColumn pairs a categorical column of a Dataset with the encoder used
+// for it.  Exactly one of the encoders is set, which keeps the fitted
+// vocabulary easy to save with a model.
+type EncodedColumn struct {
+	Column  string          `json:"column"`
+	OneHot  *OneHotEncoder  `json:"oneHot,omitempty"`
+	Ordinal *OrdinalEncoder `json:"ordinal,omitempty"`
+	Target  *TargetEncoder  `json:"target,omitempty"`
+}
+
+// Encoder returns the encoder that is set.
+func (e *EncodedColumn) Encoder() CategoryEncoder {
+	switch {
+	case e.OneHot != nil:
+		return e.OneHot
+	case e.Ordinal != nil:
+		return e.Ordinal
+	case e.Target != nil:
+		return e.Target
+	}
+	return nil
+}
+
+// Fit fits the encoder to the column of d.  t holds the target of every row
+// and is only needed for target encoding.
+func (e *EncodedColumn) Fit(d *Dataset, t []float64) error {
+	values, err := d.Category(e.Column)
+	if err != nil {
+		return err
+	}
+	encoder := e.Encoder()
+	if encoder == nil {
+		return fmt.Errorf("no encoder set for column %q", e.Column)
+	}
+	return encoder.Fit(values, t)
+}
+
+// Transform returns the encoded columns of the column of d.
+func (e *EncodedColumn) Transform(d *Dataset) ([][]float64, error) {
+	values, err := d.Category(e.Column)
+	if err != nil {
+		return nil, err
+	}
+	encoder := e.Encoder()
+	if encoder == nil {
+		return nil, fmt.Errorf("no encoder set for column %q", e.Column)
+	}
+	return encoder.Transform(values), nil
+}
+
+// Encode returns the numeric columns of d followed by the encoded columns of
+// every fitted encoder, together with the names of all of those columns.
+func Encode(d *Dataset, encoders []EncodedColumn) ([][]float64, []string, error) {
+	matrices := [][][]float64{d.Data}
+	names := append([]string(nil), d.Names...)
+	for i := range encoders {
+		encoded, err := encoders[i].Transform(d)
+		if err != nil {
+			return nil, nil, err
+		}
+		matrices = append(matrices, encoded)
+		names = append(names, encoders[i].Encoder().Names(encoders[i].Column)...)
+	}
+	return ColumnStack(matrices...), names, nil
+}
+
+// ColumnStack joins matrices with the same number of rows side by side.
+func ColumnStack(matrices ...[][]float64) [][]float64 {
+	if len(matrices) == 0 {
+		return nil
+	}
+	result := make([][]float64, len(matrices[0]))
+	for r := range result {
+		var row []float64
+		for _, m := range matrices {
+			row = append(row, m[r]...)
+		}
+		result[r] = row
+	}
+	return result
+}
+
+// uniqueSorted returns the distinct values in sorted order.
+func uniqueSorted(values []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// categoryIndex maps every category to its position.
+func categoryIndex(categories []string) map[string]int {
+	result := make(map[string]int, len(categories))
+	for i, v := range categories {
+		result[v] = i
+	}
+	return result
+}
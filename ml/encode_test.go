@@ -0,0 +1,118 @@
+package ml
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestOneHotEncoder(t *testing.T) {
+	e := &OneHotEncoder{}
+	if err := e.Fit([]string{"red", "white", "red", "rose"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	//the columns are in the sorted order of the categories, an unknown category is all 0's
+	got := e.Transform([]string{"white", "red", "sparkling", "rose"})
+	assertClose(t, "Transform", got, [][]float64{
+		{0, 0, 1},
+		{1, 0, 0},
+		{0, 0, 0},
+		{0, 1, 0},
+	}, 0)
+	if names := e.Names("type"); !reflect.DeepEqual(names, []string{"type=red", "type=rose", "type=white"}) {
+		t.Fatalf("Names = %q", names)
+	}
+
+	e.DropFirst = true
+	got = e.Transform([]string{"white", "red"})
+	assertClose(t, "Transform with DropFirst", got, [][]float64{{0, 1}, {0, 0}}, 0)
+	if names := e.Names("type"); !reflect.DeepEqual(names, []string{"type=rose", "type=white"}) {
+		t.Fatalf("Names with DropFirst = %q", names)
+	}
+
+	assertErrorContains(t, (&OneHotEncoder{}).Fit(nil, nil), "no categories")
+}
+
+func TestOrdinalEncoder(t *testing.T) {
+	//a given order is kept
+	e := &OrdinalEncoder{Categories: []string{"low", "medium", "high"}}
+	if err := e.Fit([]string{"high", "low"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	got := e.Transform([]string{"high", "low", "medium", "unknown"})
+	want := []float64{2, 0, 1}
+	for r, v := range want {
+		if got[r][0] != v {
+			t.Fatalf("Transform[%d] = %v, want %v", r, got[r][0], v)
+		}
+	}
+	if !math.IsNaN(got[3][0]) {
+		t.Fatalf("unknown category = %v, want NaN", got[3][0])
+	}
+
+	e = &OrdinalEncoder{}
+	if err := e.Fit([]string{"b", "c", "a"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "sorted", e.Transform([]string{"a", "b", "c"}), [][]float64{{0}, {1}, {2}}, 0)
+}
+
+func TestTargetEncoder(t *testing.T) {
+	values := []string{"a", "a", "b"}
+	targets := []float64{1, 3, 10}
+	prior := 14.0 / 3
+
+	tests := []struct {
+		smoothing float64
+		a         float64
+		b         float64
+	}{
+		{0, 2, 10},
+		//smoothing adds that many rows holding the prior to every category
+		{1, (1 + 3 + prior) / 3, (10 + prior) / 2},
+		{4, (1 + 3 + 4*prior) / 6, (10 + 4*prior) / 5},
+	}
+	for _, test := range tests {
+		e := &TargetEncoder{Smoothing: test.smoothing}
+		if err := e.Fit(values, targets); err != nil {
+			t.Fatal(err)
+		}
+		got := e.Transform([]string{"a", "b", "unknown"})
+		assertClose(t, "Transform", got, [][]float64{{test.a}, {test.b}, {prior}}, 1e-12)
+	}
+
+	assertErrorContains(t, (&TargetEncoder{}).Fit(values, targets[:2]), "2 targets for 3 values")
+	assertErrorContains(t, (&TargetEncoder{}).Fit(nil, nil), "no categories")
+}
+
+func TestEncode(t *testing.T) {
+	d := &Dataset{
+		Names:         []string{"x", "y"},
+		Data:          [][]float64{{1, 2}, {3, 4}},
+		CategoryNames: []string{"size", "color"},
+		Categories:    [][]string{{"small", "red"}, {"big", "blue"}},
+	}
+	encoders := []EncodedColumn{
+		{Column: "color", OneHot: &OneHotEncoder{}},
+		{Column: "size", Ordinal: &OrdinalEncoder{Categories: []string{"small", "big"}}},
+	}
+	for i := range encoders {
+		if err := encoders[i].Fit(d, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	//the numeric columns come first, then the encoded columns in the order of the encoders
+	X, names, err := Encode(d, encoders)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "Encode", X, [][]float64{{1, 2, 0, 1, 0}, {3, 4, 1, 0, 1}}, 0)
+	if want := []string{"x", "y", "color=blue", "color=red", "size"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("names = %q, want %q", names, want)
+	}
+
+	_, _, err = Encode(d, []EncodedColumn{{Column: "shape", OneHot: &OneHotEncoder{}}})
+	assertErrorContains(t, err, `no categorical column named "shape"`)
+	assertErrorContains(t, (&EncodedColumn{Column: "size"}).Fit(d, nil), `no encoder set for column "size"`)
+}
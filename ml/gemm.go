@@ -0,0 +1,99 @@
+package ml
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// Multiplier multiplies Dense matrices in cache sized blocks, spreading the
+// rows of the result over several goroutines.  The values are added up in the
+// same order as a plain triple loop, so the result is exactly the same, just
+// faster for large matrices.
+type Multiplier struct {
+	//Workers is the number of goroutines to use, it defaults to GOMAXPROCS.
+	Workers int
+	//BlockSize is the size of the square blocks the matrices are divided
+	//into, it defaults to 64.
+	BlockSize int
+}
+
+// parallelThreshold is the number of multiply-adds below which splitting the
+// work up costs more than it saves.
+const parallelThreshold = 1 << 16
+
+// Mul sets d to the matrix product a b.  d must not share memory with a or b.
+func (m Multiplier) Mul(d *Dense, a *Dense, b *Dense) {
+	if a.cols != b.rows {
+		panic(fmt.Sprintf("ml: can't multiply %d x %d by %d x %d", a.rows, a.cols, b.rows, b.cols))
+	}
+	d.checkShape(a.rows, b.cols)
+	d.Zero()
+
+	blockSize := m.BlockSize
+	if blockSize <= 0 {
+		blockSize = 64
+	}
+	workers := m.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if a.rows*a.cols*b.cols < parallelThreshold {
+		workers = 1
+	}
+
+	if workers == 1 {
+		mulBlock(d, a, b, 0, a.rows, blockSize)
+		return
+	}
+
+	//hand out panels of rows to the workers
+	panels := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for start := range panels {
+				end := start + blockSize
+				if end > a.rows {
+					end = a.rows
+				}
+				mulBlock(d, a, b, start, end, blockSize)
+			}
+		}()
+	}
+	for start := 0; start < a.rows; start += blockSize {
+		panels <- start
+	}
+	close(panels)
+	wg.Wait()
+}
+
+// mulBlock adds rows r0 through r1-1 of a b to d, a block of a's columns and
+// b's columns at a time so the part of b in use stays in cache.
+func mulBlock(d *Dense, a *Dense, b *Dense, r0 int, r1 int, blockSize int) {
+	for k0 := 0; k0 < a.cols; k0 += blockSize {
+		k1 := k0 + blockSize
+		if k1 > a.cols {
+			k1 = a.cols
+		}
+		for c0 := 0; c0 < b.cols; c0 += blockSize {
+			c1 := c0 + blockSize
+			if c1 > b.cols {
+				c1 = b.cols
+			}
+			for r := r0; r < r1; r++ {
+				aRow := a.Row(r)
+				dRow := d.Row(r)[c0:c1]
+				for k := k0; k < k1; k++ {
+					v := aRow[k]
+					bRow := b.Row(k)[c0:c1]
+					for c, w := range bRow {
+						dRow[c] += v * w
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package ml
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"testing"
+)
+
+// randomMatrix returns a rows x cols matrix of random values.
+func randomMatrix(rng *rand.Rand, rows int, cols int) [][]float64 {
+	result := Zeros(rows, cols)
+	for r := range result {
+		for c := range result[r] {
+			result[r][c] = rng.Float64()*2 - 1
+		}
+	}
+	return result
+}
+
+func TestMultiplier(t *testing.T) {
+	//m x n times n x p, large enough to be split over the workers except for
+	//the first, and mostly not multiples of the block size
+	shapes := [][3]int{
+		{3, 4, 5},
+		{1, 300, 300},
+		{300, 1, 300},
+		{300, 300, 1},
+		{97, 131, 67},
+		{128, 64, 192},
+	}
+	rng := rand.New(rand.NewSource(1))
+	for _, shape := range shapes {
+		A := randomMatrix(rng, shape[0], shape[1])
+		B := randomMatrix(rng, shape[1], shape[2])
+		want := naiveMul(A, B)
+		for _, workers := range []int{1, 2, runtime.GOMAXPROCS(0)} {
+			for _, blockSize := range []int{0, 7} {
+				name := fmt.Sprintf("%dx%dx%d/workers=%d/block=%d", shape[0], shape[1], shape[2], workers, blockSize)
+				t.Run(name, func(t *testing.T) {
+					//start from garbage to check that d is cleared first
+					d := DenseOf(randomMatrix(rng, shape[0], shape[2]))
+					Multiplier{Workers: workers, BlockSize: blockSize}.Mul(d, DenseOf(A), DenseOf(B))
+					//the values are added up in the same order, so they match exactly
+					assertClose(t, "Mul", d.Slices(), want, 0)
+				})
+			}
+		}
+	}
+}
+
+// benchmarkShapes are the shape of predicting the wine data and some square
+// matrices, m x n times n x p.
+var benchmarkShapes = [][3]int{{1599, 12, 2}, {256, 256, 256}, {512, 512, 512}}
+
+// benchmarkMul times the multiply returned by prepare for random matrices of
+// every one of benchmarkShapes.
+func benchmarkMul(b *testing.B, prepare func(A [][]float64, B [][]float64) func()) {
+	rng := rand.New(rand.NewSource(1))
+	for _, shape := range benchmarkShapes {
+		mul := prepare(randomMatrix(rng, shape[0], shape[1]), randomMatrix(rng, shape[1], shape[2]))
+		b.Run(fmt.Sprintf("%dx%dx%d", shape[0], shape[1], shape[2]), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				mul()
+			}
+		})
+	}
+}
+
+// prepareMultiplier returns a prepare function for benchmarkMul that
+// multiplies with m.
+func prepareMultiplier(m Multiplier) func(A [][]float64, B [][]float64) func() {
+	return func(A [][]float64, B [][]float64) func() {
+		a, b := DenseOf(A), DenseOf(B)
+		d := NewDense(len(A), len(B[0]), nil)
+		return func() { m.Mul(d, a, b) }
+	}
+}
+
+func BenchmarkNaive(b *testing.B) {
+	benchmarkMul(b, func(A [][]float64, B [][]float64) func() {
+		return func() { naiveMul(A, B) }
+	})
+}
+
+func BenchmarkMultiplierOneWorker(b *testing.B) {
+	benchmarkMul(b, prepareMultiplier(Multiplier{Workers: 1}))
+}
+
+func BenchmarkMultiplier(b *testing.B) {
+	benchmarkMul(b, prepareMultiplier(Multiplier{}))
+}
@@ -0,0 +1,120 @@
+package ml
+
+import (
+	"fmt"
+)
+
+// ImputeStrategy is how an Imputer chooses the value that replaces a missing
+// value.
+type ImputeStrategy int
+
+const (
+	//ImputeMean replaces missing values with the mean of the column.
+	ImputeMean ImputeStrategy = iota
+	//ImputeMedian replaces missing values with the median of the column.
+	ImputeMedian
+	//ImputeConstant replaces missing values with Imputer.Value.
+	ImputeConstant
+	//ImputeMostFrequent replaces missing values with the most frequent value
+	//of the column.
+	ImputeMostFrequent
+)
+
+// Imputer replaces missing (NaN) values.  Like the means used for
+// standardization it should be fit on the training data only and then used
+// to transform both the training and the test data.
+type Imputer struct {
+	Strategy ImputeStrategy `json:"strategy"`
+	//Value is the replacement used by ImputeConstant.
+	Value float64 `json:"value"`
+	//AddIndicator appends a column of 1's and 0's, telling whether the value
+	//was missing, for every column that had missing values during Fit.
+	AddIndicator bool `json:"addIndicator"`
+
+	//Fill is the fitted replacement value of every column.
+	Fill []float64 `json:"fill"`
+	//Indicators are the columns that get an indicator column.
+	Indicators []int `json:"indicators,omitempty"`
+}
+
+// Fit finds the replacement value of every column of X.
+func (im *Imputer) Fit(X [][]float64) error {
+	if len(X) == 0 {
+		return fmt.Errorf("imputer: no data to fit")
+	}
+	im.Fill = make([]float64, len(X[0]))
+	im.Indicators = nil
+	for c := range im.Fill {
+		values := presentColumn(X, c)
+		if len(values) < len(X) && im.AddIndicator {
+			im.Indicators = append(im.Indicators, c)
+		}
+		if im.Strategy == ImputeConstant {
+			im.Fill[c] = im.Value
+			continue
+		}
+		if len(values) == 0 {
+			return fmt.Errorf("imputer: column %d has no values", c)
+		}
+		switch im.Strategy {
+		case ImputeMean:
+			sum := 0.0
+			for _, v := range values {
+				sum += v
+			}
+			im.Fill[c] = sum / float64(len(values))
+		case ImputeMedian:
+			im.Fill[c] = median(values)
+		case ImputeMostFrequent:
+			im.Fill[c] = mostFrequent(values)
+		default:
+			return fmt.Errorf("imputer: unknown strategy %d", im.Strategy)
+		}
+	}
+	return nil
+}
+
+// Transform returns a copy of X with the missing values replaced, followed by
+// the indicator columns.  Every row of X must have the columns that were fit.
+func (im *Imputer) Transform(X [][]float64) ([][]float64, error) {
+	result := make([][]float64, len(X))
+	for r := range X {
+		if len(X[r]) != len(im.Fill) {
+			return nil, fmt.Errorf("imputer: row %d has %d columns, fit on %d", r, len(X[r]), len(im.Fill))
+		}
+		row := make([]float64, len(X[r]), len(X[r])+len(im.Indicators))
+		for c, v := range X[r] {
+			if IsMissing(v) {
+				v = im.Fill[c]
+			}
+			row[c] = v
+		}
+		for _, c := range im.Indicators {
+			indicator := 0.0
+			if IsMissing(X[r][c]) {
+				indicator = 1.0
+			}
+			row = append(row, indicator)
+		}
+		result[r] = row
+	}
+	return result, nil
+}
+
+// FitTransform fits the imputer to X and returns X transformed.
+func (im *Imputer) FitTransform(X [][]float64) ([][]float64, error) {
+	if err := im.Fit(X); err != nil {
+		return nil, err
+	}
+	return im.Transform(X)
+}
+
+// Names returns the column names of the transformed data given the names of
+// the columns that were fit.
+func (im *Imputer) Names(names []string) []string {
+	result := append([]string(nil), names...)
+	for _, c := range im.Indicators {
+		result = append(result, names[c]+" missing")
+	}
+	return result
+}
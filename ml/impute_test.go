@@ -0,0 +1,70 @@
+package ml
+
+import (
+	"math"
+	"testing"
+)
+
+func TestImputer(t *testing.T) {
+	nan := math.NaN()
+	X := [][]float64{
+		{1, nan},
+		{2, 10},
+		{nan, 20},
+		{7, 60},
+	}
+	tests := []struct {
+		name    string
+		imputer *Imputer
+		want    [][]float64
+	}{
+		{"mean", &Imputer{Strategy: ImputeMean}, [][]float64{
+			{1, 30},
+			{2, 10},
+			{10.0 / 3, 20},
+			{7, 60},
+		}},
+		{"median", &Imputer{Strategy: ImputeMedian}, [][]float64{
+			{1, 20},
+			{2, 10},
+			{2, 20},
+			{7, 60},
+		}},
+		{"constant", &Imputer{Strategy: ImputeConstant, Value: -1}, [][]float64{
+			{1, -1},
+			{2, 10},
+			{-1, 20},
+			{7, 60},
+		}},
+		//an indicator column is added for every column with missing values, in column order
+		{"indicators", &Imputer{Strategy: ImputeConstant, AddIndicator: true}, [][]float64{
+			{1, 0, 0, 1},
+			{2, 10, 0, 0},
+			{0, 20, 1, 0},
+			{7, 60, 0, 0},
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.imputer.FitTransform(X)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertClose(t, "Transform", got, test.want, 1e-12)
+			if !IsMissing(X[0][1]) {
+				t.Fatal("Transform modified X")
+			}
+		})
+	}
+}
+
+func TestImputerColumnMismatch(t *testing.T) {
+	im := &Imputer{}
+	if err := im.Fit([][]float64{{1, 2}, {3, 4}}); err != nil {
+		t.Fatal(err)
+	}
+	_, err := im.Transform([][]float64{{1, 2}, {3, 4, 5}})
+	assertErrorContains(t, err, "row 1 has 3 columns, fit on 2")
+	_, err = im.Transform([][]float64{{1}})
+	assertErrorContains(t, err, "row 0 has 1 columns, fit on 2")
+}
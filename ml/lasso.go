@@ -0,0 +1,288 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// ElasticNet is a linear model that minimizes half the mean squared error
+// plus a penalty on the weights that mixes L1 and L2:
+//
+//	alpha * L1Ratio * sum |w| + alpha * (1 - L1Ratio) / 2 * sum w^2
+//
+// The L1 part pushes the weights of features that don't help all the way to
+// zero, so the model picks out the features that matter.  With an L1Ratio of
+// 1 it is the lasso, and with 0 it is Ridge with the same alpha.  The first
+// row of W, the bias added by AddBias, is not penalized.  It implements
+// Model.
+//
+// The weights are found with coordinate descent, which solves for one weight
+// at a time with the others held still, passing over all of them until they
+// stop changing.
+type ElasticNet struct {
+	Alpha float64
+	//L1Ratio is the share of the penalty that is L1, from 0 to 1.
+	L1Ratio float64
+	//MultiTask, when T has several columns, penalizes the length of each row
+	//of W instead of each weight, so that a feature is dropped from every
+	//output at once (the multi-task lasso).  Otherwise every output column is
+	//fit on its own.
+	MultiTask bool
+
+	//MaxIterations is the most passes over the weights, it defaults to 1000.
+	MaxIterations int
+	//Tolerance stops the passes once no weight past the bias changed by more
+	//than Tolerance times the largest of them, it defaults to 1e-4.
+	Tolerance float64
+	//Random visits the weights in a new random order every pass instead of
+	//in order, using Rand or a source created from Seed.
+	Random bool
+	Seed   int64
+	Rand   *rand.Rand
+	//WarmStart starts from the weights of the last fit instead of from zero
+	//when they have the right shape, which is much quicker when alpha has
+	//only changed a little.
+	WarmStart bool
+
+	W [][]float64
+	//Iterations is the number of passes the last fit took, and Converged is
+	//false when it stopped at MaxIterations.
+	Iterations int
+	Converged  bool
+}
+
+// Lasso returns an ElasticNet with only the L1 penalty.
+func Lasso(alpha float64) *ElasticNet {
+	return &ElasticNet{Alpha: alpha, L1Ratio: 1}
+}
+
+// MultiTaskLasso returns an ElasticNet with only the L1 penalty that keeps
+// the same features for every output.
+func MultiTaskLasso(alpha float64) *ElasticNet {
+	return &ElasticNet{Alpha: alpha, L1Ratio: 1, MultiTask: true}
+}
+
+// Fit finds the weights for X and T.
+func (e *ElasticNet) Fit(X [][]float64, T [][]float64) error {
+	if len(X) == 0 {
+		return fmt.Errorf("elastic net: no rows")
+	}
+	if len(T) != len(X) {
+		return fmt.Errorf("elastic net: %d targets for %d rows", len(T), len(X))
+	}
+	if e.Alpha < 0 {
+		return fmt.Errorf("elastic net: alpha %v is negative", e.Alpha)
+	}
+	if e.L1Ratio < 0 || e.L1Ratio > 1 {
+		return fmt.Errorf("elastic net: L1 ratio %v is not between 0 and 1", e.L1Ratio)
+	}
+	n := float64(len(X))
+	inputs, outputs := len(X[0]), len(T[0])
+	maxIterations := e.MaxIterations
+	if maxIterations <= 0 {
+		maxIterations = 1000
+	}
+	tolerance := defaultValue(e.Tolerance, 1e-4)
+	l1, l2 := e.Alpha*e.L1Ratio, e.Alpha*(1-e.L1Ratio)
+
+	//every column of X is a row of columns, so that it can be read in one piece
+	columns := DenseOf(X).T()
+	w := NewDense(inputs, outputs, nil)
+	if e.WarmStart && len(e.W) == inputs && len(e.W[0]) == outputs {
+		w = DenseOf(e.W)
+	}
+	//the residuals are kept up to date as the weights change
+	residuals := NewDense(len(X), outputs, nil)
+	residuals.Mul(DenseOf(X), w)
+	residuals.Sub(DenseOf(T), residuals)
+	squares := make([]float64, inputs)
+	for j := range squares {
+		for _, x := range columns.Row(j) {
+			squares[j] += x * x
+		}
+		squares[j] /= n
+	}
+
+	order := make([]int, inputs)
+	for j := range order {
+		order[j] = j
+	}
+	var rng *rand.Rand
+	if e.Random {
+		rng = e.Rand
+		if rng == nil {
+			rng = rand.New(rand.NewSource(e.Seed))
+		}
+	}
+	z := make([]float64, outputs)
+	old := make([]float64, outputs)
+
+	e.Converged = false
+	for e.Iterations = 0; e.Iterations < maxIterations && !e.Converged; e.Iterations++ {
+		if rng != nil {
+			rng.Shuffle(len(order), func(a, b int) { order[a], order[b] = order[b], order[a] })
+		}
+		maxChange, maxWeight := 0.0, 0.0
+		for _, j := range order {
+			if squares[j] == 0 {
+				continue
+			}
+			wRow := w.Row(j)
+			xColumn := columns.Row(j)
+			copy(old, wRow)
+
+			//the least squares solution for this weight alone is z / squares[j]
+			for c := range z {
+				z[c] = 0
+			}
+			for i, x := range xColumn {
+				if x == 0 {
+					continue
+				}
+				for c, r := range residuals.Row(i) {
+					z[c] += x * r
+				}
+			}
+			for c := range z {
+				z[c] = z[c]/n + squares[j]*wRow[c]
+			}
+
+			//shrink it toward zero, the bias is left as it is
+			jl1, jl2 := l1, l2
+			if j == 0 {
+				jl1, jl2 = 0, 0
+			}
+			if e.MultiTask {
+				length := 0.0
+				for _, v := range z {
+					length += v * v
+				}
+				length = math.Sqrt(length)
+				for c := range wRow {
+					wRow[c] = 0
+					if length > jl1 {
+						wRow[c] = z[c] * (1 - jl1/length) / (squares[j] + jl2)
+					}
+				}
+			} else {
+				for c := range wRow {
+					wRow[c] = softThreshold(z[c], jl1) / (squares[j] + jl2)
+				}
+			}
+
+			//update the residuals for the change, the bias follows the other
+			//weights so it is left out of the check for convergence
+			for c := range old {
+				old[c] = wRow[c] - old[c]
+				if j > 0 {
+					maxChange = math.Max(maxChange, math.Abs(old[c]))
+					maxWeight = math.Max(maxWeight, math.Abs(wRow[c]))
+				}
+			}
+			for i, x := range xColumn {
+				if x == 0 {
+					continue
+				}
+				rRow := residuals.Row(i)
+				for c, change := range old {
+					rRow[c] -= x * change
+				}
+			}
+		}
+		e.Converged = maxChange <= tolerance*maxWeight
+	}
+	e.W = w.Slices()
+	return nil
+}
+
+// softThreshold moves v toward zero by t, stopping at zero.
+func softThreshold(v float64, t float64) float64 {
+	if v > t {
+		return v - t
+	}
+	if v < -t {
+		return v + t
+	}
+	return 0
+}
+
+// Predict runs the weights against every row of X.
+func (e *ElasticNet) Predict(X [][]float64) [][]float64 {
+	return Predict(X, e.W)
+}
+
+// NonZero returns the indexes of the rows of W, past the bias, with a weight
+// that is not zero.  These are the features the model uses.
+func (e *ElasticNet) NonZero() []int {
+	var result []int
+	for j := 1; j < len(e.W); j++ {
+		for _, v := range e.W[j] {
+			if v != 0 {
+				result = append(result, j)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// MaxAlpha returns the smallest alpha at which every weight but the bias is
+// zero, the place to start a path from.  The first column of X has to be the
+// bias added by AddBias.  It is infinite when L1Ratio is 0.
+func (e *ElasticNet) MaxAlpha(X [][]float64, T [][]float64) float64 {
+	if len(X) == 0 || e.L1Ratio == 0 {
+		return math.Inf(1)
+	}
+	n := float64(len(X))
+	//with only the bias the residuals are T less its mean, summed the same
+	//way as in Fit so that fitting at this alpha leaves every weight at zero
+	means := MeanByColumn(T)
+	largest := 0.0
+	for j := 1; j < len(X[0]); j++ {
+		z := make([]float64, len(means))
+		for i, row := range X {
+			for c, t := range T[i] {
+				z[c] += row[j] * (t - means[c])
+			}
+		}
+		for c := range z {
+			z[c] /= n
+		}
+		if e.MultiTask {
+			length := 0.0
+			for _, v := range z {
+				length += v * v
+			}
+			largest = math.Max(largest, math.Sqrt(length))
+		} else {
+			for _, v := range z {
+				largest = math.Max(largest, math.Abs(v))
+			}
+		}
+	}
+	//Fit multiplies by L1Ratio again, which mustn't round below largest
+	result := largest / e.L1Ratio
+	for result*e.L1Ratio < largest {
+		result = math.Nextafter(result, math.Inf(1))
+	}
+	return result
+}
+
+// Path fits a copy of e for every alpha, from the largest to the smallest,
+// and measures each one on validationX and validationT.  Each fit starts from
+// the weights of the one before, a warm start, so the whole path takes not
+// much longer than a single fit.  The points come back largest alpha first.
+func (e ElasticNet) Path(alphas []float64, X [][]float64, T [][]float64, validationX [][]float64, validationT [][]float64) ([]PathPoint, error) {
+	sorted := append([]float64(nil), alphas...)
+	sort.Sort(sort.Reverse(sort.Float64Slice(sorted)))
+	e.W = nil
+	last := &e
+	return RegularizationPath(func(alpha float64) Model {
+		model := *last
+		model.Alpha, model.WarmStart = alpha, true
+		last = &model
+		return last
+	}, sorted, X, T, validationX, validationT)
+}
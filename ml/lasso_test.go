@@ -0,0 +1,213 @@
+package ml
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// orthogonal returns 4 rows of a bias and three columns with a mean of 0, a
+// mean square of 1 and no overlap, where each weight of the lasso is found on
+// its own: its least squares value moved toward zero by alpha.
+func orthogonal() ([][]float64, [][]float64) {
+	X := [][]float64{
+		{1, 1, 1, 1},
+		{1, 1, -1, -1},
+		{1, -1, 1, -1},
+		{1, -1, -1, 1},
+	}
+	//T = 3 + 2 a + 0.5 b - 0.2 c
+	T := make([][]float64, len(X))
+	for i, row := range X {
+		T[i] = []float64{3 + 2*row[1] + 0.5*row[2] - 0.2*row[3]}
+	}
+	return X, T
+}
+
+// sparseProblem returns rows of 10 random features of which only 1, 4 and 7
+// are used, with a little noise.
+func sparseProblem(seed int64, outputs int) ([][]float64, [][]float64) {
+	rng := rand.New(rand.NewSource(seed))
+	X := AddBias(randomMatrix(rng, 100, 10))
+	w := Zeros(11, outputs)
+	for c := 0; c < outputs; c++ {
+		w[0][c], w[1][c], w[4][c], w[7][c] = 1, 2, -3, 1.5+float64(c)
+	}
+	T := Predict(X, w)
+	for i := range T {
+		for c := range T[i] {
+			T[i][c] += 0.01 * rng.NormFloat64()
+		}
+	}
+	return X, T
+}
+
+func TestLassoKnownSolution(t *testing.T) {
+	X, T := orthogonal()
+	tests := []struct {
+		name    string
+		model   *ElasticNet
+		want    [][]float64
+		nonZero []int
+	}{
+		{"lasso", Lasso(1), [][]float64{{3}, {1}, {0}, {0}}, []int{1}},
+		{"smaller alpha", Lasso(0.1), [][]float64{{3}, {1.9}, {0.4}, {-0.1}}, []int{1, 2, 3}},
+		//the L2 half of the penalty divides by 1 + 0.5
+		{"elastic net", &ElasticNet{Alpha: 1, L1Ratio: 0.5}, [][]float64{{3}, {1}, {0}, {0}}, []int{1}},
+		{"random order", &ElasticNet{Alpha: 0.1, L1Ratio: 1, Random: true, Seed: 1}, [][]float64{{3}, {1.9}, {0.4}, {-0.1}}, []int{1, 2, 3}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.model.Fit(X, T); err != nil {
+				t.Fatal(err)
+			}
+			assertClose(t, "W", test.model.W, test.want, 1e-12)
+			if !reflect.DeepEqual(test.model.NonZero(), test.nonZero) {
+				t.Fatalf("NonZero() = %v, want %v", test.model.NonZero(), test.nonZero)
+			}
+			if !test.model.Converged {
+				t.Fatalf("not converged after %d iterations", test.model.Iterations)
+			}
+		})
+	}
+
+	//on a larger problem it finds the features that are used
+	X, T = sparseProblem(1, 1)
+	lasso := Lasso(0.05)
+	if err := lasso.Fit(X, T); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(lasso.NonZero(), []int{1, 4, 7}) {
+		t.Fatalf("NonZero() = %v, want [1 4 7]", lasso.NonZero())
+	}
+}
+
+func TestElasticNetErrors(t *testing.T) {
+	X, T := orthogonal()
+	assertErrorContains(t, Lasso(-1).Fit(X, T), "elastic net: alpha -1 is negative")
+	assertErrorContains(t, (&ElasticNet{Alpha: 1, L1Ratio: 1.5}).Fit(X, T), "L1 ratio 1.5 is not between 0 and 1")
+	assertErrorContains(t, Lasso(1).Fit(X, T[:3]), "3 targets for 4 rows")
+	assertErrorContains(t, Lasso(1).Fit(nil, nil), "no rows")
+}
+
+func TestLassoMaxAlpha(t *testing.T) {
+	tests := []struct {
+		name    string
+		model   *ElasticNet
+		outputs int
+	}{
+		{"lasso", Lasso(0), 1},
+		{"elastic net", &ElasticNet{L1Ratio: 0.3}, 1},
+		{"several outputs", Lasso(0), 3},
+		{"multi-task", MultiTaskLasso(0), 3},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			X, T := sparseProblem(2, test.outputs)
+			maxAlpha := test.model.MaxAlpha(X, T)
+
+			//at MaxAlpha only the bias is left, which is the mean of T
+			test.model.Alpha = maxAlpha
+			if err := test.model.Fit(X, T); err != nil {
+				t.Fatal(err)
+			}
+			if len(test.model.NonZero()) != 0 {
+				t.Fatalf("weights %v at alpha %v, want only the bias", test.model.NonZero(), maxAlpha)
+			}
+			assertClose(t, "bias", test.model.W[:1], [][]float64{MeanByColumn(T)}, 1e-12)
+
+			//just below it a feature comes in
+			test.model.Alpha = 0.99 * maxAlpha
+			if err := test.model.Fit(X, T); err != nil {
+				t.Fatal(err)
+			}
+			if len(test.model.NonZero()) == 0 {
+				t.Fatalf("no weights at alpha %v, just below %v", test.model.Alpha, maxAlpha)
+			}
+		})
+	}
+}
+
+func TestElasticNetL1RatioZeroIsRidge(t *testing.T) {
+	X, T := sparseProblem(3, 2)
+	for _, alpha := range []float64{0.01, 1} {
+		want, err := Ridge(X, T, alpha)
+		if err != nil {
+			t.Fatal(err)
+		}
+		model := &ElasticNet{Alpha: alpha, Tolerance: 1e-12}
+		if err := model.Fit(X, T); err != nil {
+			t.Fatal(err)
+		}
+		assertClose(t, "W", model.W, want, 1e-9)
+	}
+}
+
+func TestElasticNetPathWarmStart(t *testing.T) {
+	X, T := sparseProblem(4, 1)
+	validationX, validationT := sparseProblem(5, 1)
+	model := ElasticNet{L1Ratio: 0.8, Tolerance: 1e-10}
+	alphas := LogSpace(model.MaxAlpha(X, T)/1000, model.MaxAlpha(X, T), 8)
+	path, err := model.Path(alphas, X, T, validationX, validationT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(path) != len(alphas) {
+		t.Fatalf("%d points for %d alphas", len(path), len(alphas))
+	}
+	for i, p := range path {
+		//largest alpha first
+		if want := alphas[len(alphas)-1-i]; p.Alpha != want {
+			t.Fatalf("point %d has alpha %v, want %v", i, p.Alpha, want)
+		}
+		cold := model
+		cold.Alpha = p.Alpha
+		if err := cold.Fit(X, T); err != nil {
+			t.Fatal(err)
+		}
+		assertClose(t, "warm started W", p.Model.(*ElasticNet).W, cold.W, 1e-8)
+	}
+	if model.W != nil {
+		t.Fatal("Path changed the weights of the model it was called on")
+	}
+}
+
+func TestMultiTaskLassoRows(t *testing.T) {
+	X, T := sparseProblem(6, 3)
+	//make feature 2 matter a little, to one output only
+	for i := range T {
+		T[i][0] += 0.1 * X[i][2]
+	}
+	alpha := 0.2
+	multi := MultiTaskLasso(alpha)
+	if err := multi.Fit(X, T); err != nil {
+		t.Fatal(err)
+	}
+	zeroRows := 0
+	for j, row := range multi.W[1:] {
+		zeros := 0
+		for _, v := range row {
+			if v == 0 {
+				zeros++
+			}
+		}
+		if zeros != 0 && zeros != len(row) {
+			t.Fatalf("row %d of W is %v, want all or none of it zero", j+1, row)
+		}
+		if zeros == len(row) {
+			zeroRows++
+		}
+	}
+	if !reflect.DeepEqual(multi.NonZero(), []int{1, 4, 7}) || zeroRows != 7 {
+		t.Fatalf("NonZero() = %v with %d zero rows, want [1 4 7]", multi.NonZero(), zeroRows)
+	}
+
+	//fit on their own the outputs keep feature 2 for the first output only
+	separate := Lasso(0.02)
+	if err := separate.Fit(X, T); err != nil {
+		t.Fatal(err)
+	}
+	if separate.W[2][0] == 0 || separate.W[2][1] != 0 || separate.W[2][2] != 0 {
+		t.Fatalf("row 2 of the separate lasso is %v, want only the first weight", separate.W[2])
+	}
+}
@@ -0,0 +1,137 @@
+package ml
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// LIBSVMOptions configures LoadLIBSVM.  The zero value reads 1 based feature
+// indexes and makes X as wide as the largest index found.
+type LIBSVMOptions struct {
+	//Features is the number of columns of X.  When 0 it is the largest
+	//feature index in the file, set it so that files holding the training and
+	//test data load with the same width.
+	Features int
+	//ZeroBased reads feature indexes starting at 0 instead of 1.
+	ZeroBased bool
+}
+
+// LoadLIBSVM reads a file in the LIBSVM (SVMlight) format, where each line is
+// a target followed by the non-zero features as index:value pairs:
+//
+//	5 1:7.4 2:0.7 11:9.4
+//
+// Several targets can be given separated by commas, "10.5,6 1:7.4".  qid:
+// pairs and anything after a '#' are ignored.  It returns the features as a
+// sparse matrix and the targets with one column per target.
+func LoadLIBSVM(filename string, options LIBSVMOptions) (*CSR, [][]float64, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	X, T, err := ReadLIBSVM(file, options)
+	if e, ok := err.(*ParseError); ok {
+		e.Filename = filename
+	} else if err != nil {
+		err = fmt.Errorf("%s: %v", filename, err)
+	}
+	return X, T, err
+}
+
+// ReadLIBSVM reads LIBSVM format text, see LoadLIBSVM.
+func ReadLIBSVM(r io.Reader, options LIBSVMOptions) (*CSR, [][]float64, error) {
+	X := &CSR{indptr: []int{0}}
+	var T [][]float64
+	first := 1
+	if options.ZeroBased {
+		first = 0
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<30)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		if line == 1 {
+			text = strings.TrimPrefix(text, "\uFEFF")
+		}
+		if i := strings.IndexByte(text, '#'); i >= 0 {
+			text = text[:i]
+		}
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+
+		var targets []float64
+		for _, field := range strings.Split(fields[0], ",") {
+			t, err := strconv.ParseFloat(field, 64)
+			if err != nil {
+				return nil, nil, &ParseError{Line: line, Column: 1, Err: err}
+			}
+			targets = append(targets, t)
+		}
+		if len(T) > 0 && len(targets) != len(T[0]) {
+			return nil, nil, &ParseError{Line: line, Column: 1,
+				Err: fmt.Errorf("expected %d targets, found %d", len(T[0]), len(targets))}
+		}
+
+		previous := -1
+		for f, field := range fields[1:] {
+			colon := strings.IndexByte(field, ':')
+			if colon < 0 {
+				return nil, nil, &ParseError{Line: line, Column: f + 2, Err: fmt.Errorf("expected index:value, found %q", field)}
+			}
+			if field[:colon] == "qid" {
+				continue
+			}
+			index, err := strconv.Atoi(field[:colon])
+			if err != nil {
+				return nil, nil, &ParseError{Line: line, Column: f + 2, Err: err}
+			}
+			index -= first
+			if index < 0 || (options.Features > 0 && index >= options.Features) {
+				return nil, nil, &ParseError{Line: line, Column: f + 2, Err: fmt.Errorf("feature index %s out of range", field[:colon])}
+			}
+			if index <= previous {
+				return nil, nil, &ParseError{Line: line, Column: f + 2, Err: fmt.Errorf("feature index %s is not increasing", field[:colon])}
+			}
+			previous = index
+			value, err := strconv.ParseFloat(field[colon+1:], 64)
+			if err != nil {
+				return nil, nil, &ParseError{Line: line, Column: f + 2, Err: err}
+			}
+			//an explicit 0 isn't stored but still counts towards the width
+			if index >= X.cols {
+				X.cols = index + 1
+			}
+			if value == 0 {
+				continue
+			}
+
+			X.indices = append(X.indices, index)
+			X.data = append(X.data, value)
+		}
+		X.indptr = append(X.indptr, len(X.indices))
+		X.rows++
+		T = append(T, targets)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	if X.rows == 0 {
+		return nil, nil, fmt.Errorf("no data")
+	}
+
+	if options.Features > 0 {
+		X.cols = options.Features
+	}
+	return X, T, nil
+}
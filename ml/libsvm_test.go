@@ -0,0 +1,76 @@
+package ml
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadLIBSVM(t *testing.T) {
+	text := "# wine\n5 1:7.4 3:0.7\n\n6 qid:2 2:1.5 4:0 # a comment\n7\n"
+	X, T, err := ReadLIBSVM(strings.NewReader(text), LIBSVMOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	//the width is the largest index, an explicit 0 is not stored
+	assertClose(t, "X", X.Slices(), [][]float64{{7.4, 0, 0.7, 0}, {0, 1.5, 0, 0}, {0, 0, 0, 0}}, 0)
+	assertClose(t, "T", T, [][]float64{{5}, {6}, {7}}, 0)
+	if X.NNZ() != 3 {
+		t.Fatalf("NNZ() = %d, want 3", X.NNZ())
+	}
+
+	X, T, err = ReadLIBSVM(strings.NewReader("10.5,6 0:1\n9,5 2:2\n"), LIBSVMOptions{ZeroBased: true, Features: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "X zero based", X.Slices(), [][]float64{{1, 0, 0, 0, 0}, {0, 0, 2, 0, 0}}, 0)
+	assertClose(t, "T with several targets", T, [][]float64{{10.5, 6}, {9, 5}}, 0)
+}
+
+func TestReadLIBSVMErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		options LIBSVMOptions
+		line    int
+		column  int
+		message string
+	}{
+		{"index below 1", "1 1:2\n2 0:3\n", LIBSVMOptions{}, 2, 2, "feature index 0 out of range"},
+		{"index past Features", "1 1:2 3:4\n", LIBSVMOptions{Features: 2}, 1, 3, "feature index 3 out of range"},
+		{"index not a number", "1 1:2\n\n2 x:3\n", LIBSVMOptions{}, 3, 2, "invalid syntax"},
+		{"unsorted index", "1 1:2\n2 4:3 2:1\n", LIBSVMOptions{}, 2, 3, "feature index 2 is not increasing"},
+		{"repeated index", "1 2:2 2:3\n", LIBSVMOptions{}, 1, 3, "feature index 2 is not increasing"},
+		{"missing label", "1 1:2\n1:2 2:3\n", LIBSVMOptions{}, 2, 1, "invalid syntax"},
+		{"different number of labels", "1,2 1:2\n1 1:2\n", LIBSVMOptions{}, 2, 1, "expected 2 targets, found 1"},
+		{"no colon", "1 1:2 3\n", LIBSVMOptions{}, 1, 3, `expected index:value, found "3"`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, err := ReadLIBSVM(strings.NewReader(test.text), test.options)
+			parseErr, ok := err.(*ParseError)
+			if !ok {
+				t.Fatalf("got error %v, want a *ParseError", err)
+			}
+			if parseErr.Line != test.line || parseErr.Column != test.column {
+				t.Fatalf("got %s, want line %d, column %d", parseErr, test.line, test.column)
+			}
+			assertErrorContains(t, err, test.message)
+		})
+	}
+
+	_, _, err := ReadLIBSVM(strings.NewReader("# nothing\n"), LIBSVMOptions{})
+	assertErrorContains(t, err, "no data")
+}
+
+func TestLoadLIBSVM(t *testing.T) {
+	filename := writeTemp(t, "data.libsvm", "1 1:2\n2 2:x\n")
+	_, _, err := LoadLIBSVM(filename, LIBSVMOptions{})
+	assertErrorContains(t, err, filename+": line 2, column 2")
+
+	_, _, err = LoadLIBSVM(filepath.Join(os.TempDir(), "no such file.libsvm"), LIBSVMOptions{})
+	if !os.IsNotExist(err) {
+		t.Fatalf("got error %v, want one for a missing file", err)
+	}
+}
@@ -0,0 +1,556 @@
+package ml
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Zeros returns a rows x cols matrix of zeros.
+func Zeros(rows int, cols int) [][]float64 {
+	result := make([][]float64, rows)
+	for r := range result {
+		result[r] = make([]float64, cols)
+	}
+	return result
+}
+
+// Predict runs the linear model w against every row of X.  Large batches are
+// spread over all of the CPUs.
+func Predict(X [][]float64, w [][]float64) [][]float64 {
+	if len(X) == 0 {
+		return nil
+	}
+	result := NewDense(len(X), len(w[0]), nil)
+	Multiplier{}.Mul(result, DenseOf(X), DenseOf(w))
+	return result.Slices()
+}
+
+// FullBatch is a batch size that puts every sample in one batch, for full
+// batch gradient descent.
+const FullBatch = math.MaxInt32
+
+// SGD trains a linear model with gradient descent.  By default it is
+// stochastic, updating the weights after every single sample.
+type SGD struct {
+	LearningRate float64
+	Epochs       int
+
+	//BatchSize is the number of samples whose gradients are averaged for each
+	//update of the weights.  0 or 1 updates after every sample, FullBatch
+	//updates once per epoch.  Because the gradient is an average the same
+	//learning rate works for any batch size.
+	BatchSize int
+
+	//NoShuffle visits the samples in the same order every epoch.  By default
+	//the order is shuffled before each epoch so that the updates are not
+	//biased toward the last samples, using a source created from Seed so
+	//that runs can be reproduced.
+	NoShuffle bool
+	Seed      int64
+	//Rand, when set, is used for shuffling instead of a source created from
+	//Seed.
+	Rand *rand.Rand
+
+	//Optimizer, when set, decides how each gradient changes the weights,
+	//otherwise plain gradient descent is used.  It keeps its state after
+	//training, so a new one is needed for every model.
+	Optimizer Optimizer
+	//Schedule, when set, changes the learning rate from epoch to epoch.
+	Schedule Schedule
+	//L2 adds L2 times the weights to every gradient, which keeps the weights
+	//small (ridge regression).  The first row of w, the bias added by
+	//AddBias, is left out.
+	L2 float64
+	//RateFinder, when set, runs a learning rate range test from the starting
+	//weights and trains with the rate it suggests in place of LearningRate.
+	RateFinder *RateFinder
+
+	//ValidationX and ValidationT, when set, are held out data whose RMSE is
+	//measured after every epoch, for OnReport, for a Schedule that is an
+	//Observer and for EarlyStopping.
+	ValidationX [][]float64
+	ValidationT [][]float64
+	//EarlyStopping, when set, stops training once the validation error stops
+	//improving and keeps the best weights.
+	EarlyStopping *EarlyStopping
+	//Convergence, when set, stops training once the loss or the gradient
+	//stops changing.
+	Convergence *Convergence
+	//MaxLossGrowth is how many times the loss of the first epoch the loss of
+	//a later epoch can reach before training stops with a DivergenceError.
+	//It defaults to DefaultMaxLossGrowth.
+	MaxLossGrowth float64
+
+	//OnEpoch, when set, is called after every epoch with the training RMSE of
+	//each output column.
+	OnEpoch func(epoch int, rmse []float64)
+	//OnReport, when set, is called after every epoch with the learning rate
+	//used and the training and validation errors.
+	OnReport func(r EpochReport)
+	//OnCheckpoint, when set, is called after every epoch with a copy of the
+	//weights and the optimizer that can be saved and resumed from.
+	OnCheckpoint func(c *Checkpoint)
+
+	//first is the epoch to start from when resuming, and resumed the state
+	//of early stopping to start from.
+	first   int
+	resumed *EarlyStoppingState
+}
+
+// EpochReport describes one epoch of training.
+type EpochReport struct {
+	Epoch int
+	//Rate is the learning rate used for the epoch.
+	Rate      float64
+	TrainRMSE []float64
+	//ValidationRMSE is nil when SGD has no validation data.
+	ValidationRMSE []float64
+}
+
+// Train runs the configured number of epochs over X and T starting from the
+// weights w, and returns the trained weights.  w is not modified.  When
+// training blows up it returns the weights as they were along with a
+// *DivergenceError.
+func (s SGD) Train(X [][]float64, T [][]float64, w [][]float64) ([][]float64, error) {
+	weights := DenseOf(w)
+	err := s.TrainDense(DenseOf(X), DenseOf(T), weights)
+	return weights.Slices(), err
+}
+
+// Resume carries on training over X and T from a checkpoint, running the
+// epochs from c.Epoch up to Epochs with the weights and optimizer of c.  Early
+// stopping and a ReduceOnPlateau schedule carry on from the state saved in c.
+// When the order is shuffled from Seed it picks up the same order the original
+// run would have used, so the result is the same as training without
+// stopping.
+func (s SGD) Resume(c *Checkpoint, X [][]float64, T [][]float64) ([][]float64, error) {
+	s.first = c.Epoch
+	if c.Optimizer != nil {
+		s.Optimizer = c.Optimizer
+	}
+	s.resumed = c.EarlyStopping
+	if plateau := plateauOf(s.Schedule); plateau != nil && c.Plateau != nil {
+		plateau.Scale, plateau.Best, plateau.Wait, plateau.Cooling = c.Plateau.Scale, c.Plateau.Best, c.Plateau.Wait, c.Plateau.Cooling
+	}
+	return s.Train(X, T, c.W)
+}
+
+// batchSize returns the number of samples in a full batch.
+func (s SGD) batchSize(samples int) int {
+	if s.BatchSize <= 1 {
+		return 1
+	}
+	if s.BatchSize > samples {
+		return samples
+	}
+	return s.BatchSize
+}
+
+func (s SGD) rand() *rand.Rand {
+	if s.Rand != nil {
+		return s.Rand
+	}
+	return rand.New(rand.NewSource(s.Seed))
+}
+
+// order returns the order to visit the samples in, and a function that
+// shuffles it for the next epoch.  When resuming the order is shuffled once
+// for every epoch that has already run.
+func (s SGD) order(samples int) ([]int, func()) {
+	order := make([]int, samples)
+	for j := range order {
+		order[j] = j
+	}
+	if s.NoShuffle {
+		return order, func() {}
+	}
+	rng := s.rand()
+	shuffle := func() {
+		rng.Shuffle(len(order), func(a, b int) { order[a], order[b] = order[b], order[a] })
+	}
+	for i := 0; i < s.first; i++ {
+		shuffle()
+	}
+	return order, shuffle
+}
+
+func (s SGD) optimizer() Optimizer {
+	if s.Optimizer == nil {
+		return &GradientDescent{}
+	}
+	return s.Optimizer
+}
+
+// rate returns the learning rate for an epoch.
+func (s SGD) rate(epoch int) float64 {
+	if s.Schedule == nil {
+		return s.LearningRate
+	}
+	return s.Schedule.Rate(epoch, s.LearningRate)
+}
+
+// addL2 adds the gradient of the L2 penalty to gradient, leaving out the bias
+// row.
+func (s SGD) addL2(gradient *Dense, w *Dense) {
+	if s.L2 == 0 {
+		return
+	}
+	for r := 1; r < w.rows; r++ {
+		gRow := gradient.Row(r)
+		for c, v := range w.Row(r) {
+			gRow[c] += s.L2 * v
+		}
+	}
+}
+
+// endEpoch reports the training and validation errors and a checkpoint after
+// an epoch and passes the error on to the schedule.  It returns true when
+// early stopping says to stop.
+func (s SGD) endEpoch(epoch int, rate float64, samples int, sqerrorSum []float64, w *Dense, held *heldOut) bool {
+	observer, observing := s.Schedule.(Observer)
+	if s.OnEpoch == nil && s.OnReport == nil && !observing && s.OnCheckpoint == nil && s.EarlyStopping == nil {
+		return false
+	}
+
+	rmse := make([]float64, len(sqerrorSum))
+	for c := range rmse {
+		rmse[c] = math.Sqrt(sqerrorSum[c] / float64(samples))
+	}
+	var predicted [][]float64
+	var validationRMSE []float64
+	if held != nil {
+		predicted = held.predict(w.Slices())
+		validationRMSE = RMSE(predicted, held.T)
+	}
+
+	if s.OnEpoch != nil {
+		s.OnEpoch(epoch, rmse)
+	}
+	if s.OnReport != nil {
+		s.OnReport(EpochReport{Epoch: epoch, Rate: rate, TrainRMSE: rmse, ValidationRMSE: validationRMSE})
+	}
+	if observing {
+		if validationRMSE != nil {
+			observer.Observe(epoch, mean(validationRMSE))
+		} else {
+			observer.Observe(epoch, mean(rmse))
+		}
+	}
+	//check first so that the checkpoint holds the state after this epoch
+	stop := s.EarlyStopping != nil && held != nil && s.EarlyStopping.check(epoch, predicted, held.T, w)
+	if s.OnCheckpoint != nil {
+		checkpoint := s.snapshot(epoch+1, w)
+		if s.EarlyStopping != nil && held != nil {
+			checkpoint.EarlyStopping = s.EarlyStopping.state()
+		}
+		s.OnCheckpoint(checkpoint)
+	}
+	return stop
+}
+
+// startEarlyStopping gets early stopping ready for a run, and returns a
+// function that puts back the best weights at the end.
+func (s SGD) startEarlyStopping(w *Dense, held *heldOut) func() {
+	if s.EarlyStopping == nil || held == nil {
+		return func() {}
+	}
+	s.EarlyStopping.start(w, s.resumed)
+	return func() { s.EarlyStopping.restore(w) }
+}
+
+// TrainDense runs the configured number of epochs over X and T, updating the
+// weights w in place.  Apart from the error sums reported to OnEpoch, the
+// buffers for a batch and the validation data it allocates nothing, however
+// large X is.  When training blows up it stops with a *DivergenceError.
+func (s SGD) TrainDense(X *Dense, T *Dense, w *Dense) error {
+	if err := checkOptimizer(s.Optimizer, w); err != nil {
+		return err
+	}
+	X, T, held := s.holdOutDense(X, T)
+	if s.RateFinder != nil {
+		if err := s.RateFinder.FindDense(s, X, T, w); err != nil {
+			return err
+		}
+		s.LearningRate = s.RateFinder.Rate
+	}
+	defer s.startEarlyStopping(w, held)()
+	monitor := s.newMonitor(w)
+	samples, inputs := X.Dims()
+	_, outputs := w.Dims()
+	y := make([]float64, outputs)
+	err := make([]float64, outputs)
+	sqerrorSum := make([]float64, outputs)
+	order, shuffle := s.order(samples)
+	optimizer := s.optimizer()
+
+	batchSize := s.batchSize(samples)
+	gradient := NewDense(inputs, outputs, nil)
+	var xBatch, tBatch, yBatch, errBatch *Dense
+	if batchSize > 1 {
+		if !s.NoShuffle {
+			//shuffled rows are copied together into a batch
+			xBatch = NewDense(batchSize, inputs, nil)
+			tBatch = NewDense(batchSize, outputs, nil)
+		}
+		yBatch = NewDense(batchSize, outputs, nil)
+		errBatch = NewDense(batchSize, outputs, nil)
+	}
+
+	for i := s.first; i < s.Epochs; i++ {
+		for c := range sqerrorSum {
+			sqerrorSum[c] = 0
+		}
+		shuffle()
+		rate := s.rate(i)
+
+		for start, step := 0, 0; start < samples; start, step = start+batchSize, step+1 {
+			end := start + batchSize
+			if end > samples {
+				end = samples
+			}
+			if batchSize > 1 {
+				n := end - start
+				var xb, tb *Dense
+				if s.NoShuffle {
+					xb = X.Slice(start, end, 0, inputs)
+					tb = T.Slice(start, end, 0, outputs)
+				} else {
+					xb = xBatch.Slice(0, n, 0, inputs)
+					tb = tBatch.Slice(0, n, 0, outputs)
+					for r := 0; r < n; r++ {
+						copy(xb.Row(r), X.Row(order[start+r]))
+						copy(tb.Row(r), T.Row(order[start+r]))
+					}
+				}
+				yb := yBatch.Slice(0, n, 0, outputs)
+				eb := errBatch.Slice(0, n, 0, outputs)
+
+				//predict the whole batch at once and find the errors
+				yb.Mul(xb, w)
+				eb.Sub(tb, yb)
+
+				//the gradient of the squared error averaged over the batch is -X^T err / n
+				gradient.MulTransA(xb, eb)
+				gradient.Scale(-1/float64(n), gradient)
+
+				//add to sqerrorSum
+				for r := 0; r < n; r++ {
+					for c, e := range eb.Row(r) {
+						sqerrorSum[c] += e * e
+					}
+				}
+			} else {
+				xRow := X.Row(order[start])
+				tRow := T.Row(order[start])
+
+				//multiply the sample by weight matrix to get predicted value for y using model
+				for c := 0; c < outputs; c++ {
+					sum := 0.0
+					for k := 0; k < inputs; k++ {
+						sum += xRow[k] * w.At(k, c)
+					}
+					y[c] = sum
+				}
+
+				//find the error
+				for c := 0; c < outputs; c++ {
+					err[c] = tRow[c] - y[c]
+				}
+
+				//the gradient of the squared error is -x err
+				for k := 0; k < inputs; k++ {
+					gRow := gradient.Row(k)
+					for c := range gRow {
+						gRow[c] = -(xRow[k] * err[c])
+					}
+				}
+
+				//add to sqerrorSum
+				for c := 0; c < outputs; c++ {
+					sqerrorSum[c] += err[c] * err[c]
+				}
+			}
+
+			//add the change to the weight matrix
+			s.addL2(gradient, w)
+			optimizer.Update(w, gradient, rate)
+			if err := monitor.step(i, step, end, sqerrorSum, gradient); err != nil {
+				return err
+			}
+		}
+
+		converged, err := monitor.endEpoch(i, samples, sqerrorSum, w)
+		if err != nil {
+			return err
+		}
+		if s.endEpoch(i, rate, samples, sqerrorSum, w, held) || converged {
+			break
+		}
+	}
+	return nil
+}
+
+// TrainCSR runs the configured number of epochs over the sparse inputs X and
+// the targets T, updating the weights w in place.  Each step only reads and
+// updates the rows of w for the non-zero inputs of the samples in the batch,
+// so the cost of an epoch grows with the number of non-zero values rather
+// than the width of X.  Updating after every sample, the weights come out the
+// same as TrainDense on the same values.
+//
+// With an Optimizer or L2 every step updates all of w, because the optimizers
+// keep moving weights whose gradient is zero and L2 shrinks every weight.
+func (s SGD) TrainCSR(X *CSR, T *Dense, w *Dense) error {
+	if err := checkOptimizer(s.Optimizer, w); err != nil {
+		return err
+	}
+	X, T, held := s.holdOutCSR(X, T)
+	if s.RateFinder != nil {
+		if err := s.RateFinder.FindCSR(s, X, T, w); err != nil {
+			return err
+		}
+		s.LearningRate = s.RateFinder.Rate
+	}
+	defer s.startEarlyStopping(w, held)()
+	monitor := s.newMonitor(w)
+	samples, _ := X.Dims()
+	inputs, outputs := w.Dims()
+	batchSize := s.batchSize(samples)
+	errBatch := NewDense(batchSize, outputs, nil)
+	sqerrorSum := make([]float64, outputs)
+	order, shuffle := s.order(samples)
+	var gradient *Dense
+	var optimizer Optimizer
+	if s.Optimizer != nil || s.L2 != 0 {
+		gradient = NewDense(inputs, outputs, nil)
+		optimizer = s.optimizer()
+	}
+
+	for i := s.first; i < s.Epochs; i++ {
+		for c := range sqerrorSum {
+			sqerrorSum[c] = 0
+		}
+		shuffle()
+		rate := s.rate(i)
+
+		for start, step := 0, 0; start < samples; start, step = start+batchSize, step+1 {
+			end := start + batchSize
+			if end > samples {
+				end = samples
+			}
+			batchRate := rate / float64(end-start)
+
+			//find the error of the predicted value using only the non-zero inputs,
+			//every sample in the batch uses the same weights
+			for j := start; j < end; j++ {
+				columns, values := X.RowNonZeros(order[j])
+				tRow := T.Row(order[j])
+				err := errBatch.Row(j - start)
+				for c := 0; c < outputs; c++ {
+					sum := 0.0
+					for p, k := range columns {
+						sum += values[p] * w.At(k, c)
+					}
+					err[c] = tRow[c] - sum
+				}
+			}
+
+			if gradient != nil {
+				gradient.Zero()
+			}
+			for j := start; j < end; j++ {
+				columns, values := X.RowNonZeros(order[j])
+				err := errBatch.Row(j - start)
+				if gradient != nil {
+					//sum up the gradient for the optimizer
+					for p, k := range columns {
+						gRow := gradient.Row(k)
+						for c := range gRow {
+							gRow[c] -= values[p] * err[c]
+						}
+					}
+				} else {
+					//add the averaged change to the rows of the weight matrix for the non-zero inputs
+					for p, k := range columns {
+						wRow := w.Row(k)
+						for c := range wRow {
+							wRow[c] += values[p] * err[c] * batchRate
+						}
+					}
+					if monitor.gradientSum != nil {
+						for p, k := range columns {
+							gRow := monitor.gradientSum.Row(k)
+							for c := range gRow {
+								gRow[c] -= values[p] * err[c] / float64(end-start)
+							}
+						}
+					}
+				}
+
+				//add to sqerrorSum
+				for c := 0; c < outputs; c++ {
+					sqerrorSum[c] += err[c] * err[c]
+				}
+			}
+			if gradient != nil {
+				gradient.Scale(1/float64(end-start), gradient)
+				s.addL2(gradient, w)
+				optimizer.Update(w, gradient, rate)
+			}
+			if err := monitor.step(i, step, end, sqerrorSum, gradient); err != nil {
+				return err
+			}
+		}
+
+		converged, err := monitor.endEpoch(i, samples, sqerrorSum, w)
+		if err != nil {
+			return err
+		}
+		if s.endEpoch(i, rate, samples, sqerrorSum, w, held) || converged {
+			break
+		}
+	}
+	return nil
+}
+
+// Copy returns a deep copy of a matrix.
+func Copy(m [][]float64) [][]float64 {
+	result := make([][]float64, len(m))
+	for r := range m {
+		result[r] = append([]float64(nil), m[r]...)
+	}
+	return result
+}
+
+// LeastSquares returns the weights w that minimize the squared error of X w
+// against T directly.  It solves the least squares problem with a QR
+// decomposition of X, which never forms the normal equations X^T X w = X^T T
+// and so doesn't square the condition number of X.  T may have several
+// columns, one per output.
+func LeastSquares(X [][]float64, T [][]float64) ([][]float64, error) {
+	qr, err := NewQR(X)
+	if err != nil {
+		return nil, err
+	}
+	return qr.Solve(T)
+}
+
+// LeastSquaresRegression is a linear model fit with LeastSquares.  It
+// implements Model.
+type LeastSquaresRegression struct {
+	W [][]float64
+}
+
+// Fit finds the weights for X and T.
+func (m *LeastSquaresRegression) Fit(X [][]float64, T [][]float64) error {
+	w, err := LeastSquares(X, T)
+	if err != nil {
+		return err
+	}
+	m.W = w
+	return nil
+}
+
+// Predict runs the weights against every row of X.
+func (m *LeastSquaresRegression) Predict(X [][]float64) [][]float64 {
+	return Predict(X, m.W)
+}
@@ -0,0 +1,191 @@
+package ml
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// line returns samples of t = 1 + 2 x1 - 3 x2 with a bias column, the
+// targets fit exactly by the weights [1 2 -3].
+func line() ([][]float64, [][]float64) {
+	var X, T [][]float64
+	for i := 0; i < 20; i++ {
+		x1 := float64(i%5) - 2
+		x2 := float64(i%4)/2 - 1
+		X = append(X, []float64{1, x1, x2})
+		T = append(T, []float64{1 + 2*x1 - 3*x2})
+	}
+	return X, T
+}
+
+func TestLeastSquares(t *testing.T) {
+	X, T := line()
+	w, err := LeastSquares(X, T)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "w", w, [][]float64{{1}, {2}, {-3}}, 1e-12)
+}
+
+func TestSGDMatchesLeastSquares(t *testing.T) {
+	X, T := line()
+	exact, err := LeastSquares(X, T)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		sgd  SGD
+	}{
+		{"stochastic", SGD{LearningRate: 0.05, Epochs: 500, Seed: 1}},
+		{"in order", SGD{LearningRate: 0.05, Epochs: 500, NoShuffle: true}},
+		{"mini-batch", SGD{LearningRate: 0.1, Epochs: 1000, BatchSize: 4, Seed: 1}},
+		{"full batch", SGD{LearningRate: 0.2, Epochs: 2000, BatchSize: FullBatch}},
+		{"adam", SGD{LearningRate: 0.05, Epochs: 1000, Seed: 1, Optimizer: &Adam{}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w, err := test.sgd.Train(X, T, Zeros(3, 1))
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertClose(t, "w", w, exact, 1e-6)
+		})
+	}
+}
+
+func TestSGDDoesNotModifyWeights(t *testing.T) {
+	X, T := line()
+	w := Zeros(3, 1)
+	if _, err := (SGD{LearningRate: 0.05, Epochs: 2}).Train(X, T, w); err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "w", w, Zeros(3, 1), 0)
+}
+
+func TestSGDSeed(t *testing.T) {
+	X, T := line()
+	sgd := SGD{LearningRate: 0.01, Epochs: 3, Seed: 3}
+	a, err := sgd.Train(X, T, Zeros(3, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := sgd.Train(X, T, Zeros(3, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "the same seed", a, b, 0)
+}
+
+func TestPredict(t *testing.T) {
+	X := [][]float64{{1, 2}, {1, -1}}
+	w := [][]float64{{0.5, 1}, {2, 0}}
+	assertClose(t, "Predict", Predict(X, w), [][]float64{{4.5, 1}, {-1.5, 1}}, 0)
+}
+
+func TestSGDBatchSteps(t *testing.T) {
+	X := [][]float64{{1, 1}, {1, 2}, {1, 3}}
+	T := [][]float64{{2}, {3}, {5}}
+	w := [][]float64{{0}, {1}}
+
+	//the errors against w are 1, 1 and 2, so the averaged gradient is
+	//-X^T err / 3 = -[4 9] / 3 and one step of 0.1 gives w + [4/30 9/30]
+	tests := []struct {
+		name string
+		sgd  SGD
+		want [][]float64
+	}{
+		{"one full batch step", SGD{LearningRate: 0.1, Epochs: 1, BatchSize: FullBatch}, [][]float64{{2.0 / 15}, {13.0 / 10}}},
+		{"two full batch steps", SGD{LearningRate: 0.1, Epochs: 2, BatchSize: FullBatch}, [][]float64{{29.0 / 150}, {43.0 / 30}}},
+		//a batch size larger than the samples is one full batch
+		{"batch larger than the samples", SGD{LearningRate: 0.1, Epochs: 1, BatchSize: 10}, [][]float64{{2.0 / 15}, {13.0 / 10}}},
+		//rows 0 and 1 are averaged, then row 2 is a batch of its own
+		{"uneven mini-batches", SGD{LearningRate: 0.1, Epochs: 1, BatchSize: 2, NoShuffle: true}, [][]float64{{49.0 / 200}, {317.0 / 200}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var rmse []float64
+			test.sgd.OnEpoch = func(epoch int, r []float64) {
+				if epoch == 0 {
+					rmse = r
+				}
+			}
+			got, err := test.sgd.Train(X, T, w)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertClose(t, "w", got, test.want, 1e-12)
+			//a full batch measures every error against the starting weights
+			if test.sgd.BatchSize != 2 {
+				assertClose(t, "RMSE", [][]float64{rmse}, [][]float64{{math.Sqrt(2)}}, 1e-12)
+			}
+		})
+	}
+}
+
+func TestSGDNoShuffleKeepsOrder(t *testing.T) {
+	X := [][]float64{{1, 1}, {1, 2}, {1, 3}}
+	T := [][]float64{{2}, {3}, {5}}
+
+	//one update per sample, by hand, in the order of the rows
+	want := [][]float64{{0}, {1}}
+	for r := range X {
+		err := T[r][0] - (X[r][0]*want[0][0] + X[r][1]*want[1][0])
+		want[0][0] += 0.1 * X[r][0] * err
+		want[1][0] += 0.1 * X[r][1] * err
+	}
+
+	got, err := (SGD{LearningRate: 0.1, Epochs: 1, BatchSize: 1, NoShuffle: true}).Train(X, T, [][]float64{{0}, {1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "w", got, want, 1e-12)
+
+	//the same rows in another order give other weights, so the order was kept
+	reversed, err := (SGD{LearningRate: 0.1, Epochs: 1, NoShuffle: true}).Train(Rows(X, []int{2, 1, 0}), Rows(T, []int{2, 1, 0}), [][]float64{{0}, {1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(reversed[1][0]-want[1][0]) < 1e-6 {
+		t.Fatalf("reversed rows gave the same weights %v", reversed)
+	}
+}
+
+func TestSGDShuffleOrder(t *testing.T) {
+	const samples, epochs = 10, 5
+	orders := func(s SGD) [][]int {
+		order, shuffle := s.order(samples)
+		var result [][]int
+		for i := 0; i < epochs; i++ {
+			shuffle()
+			result = append(result, append([]int(nil), order...))
+		}
+		return result
+	}
+
+	a := orders(SGD{Seed: 4})
+	for i, order := range a {
+		seen := make([]bool, samples)
+		for _, r := range order {
+			seen[r] = true
+		}
+		for r, ok := range seen {
+			if !ok {
+				t.Fatalf("epoch %d order %v is missing row %d", i, order, r)
+			}
+		}
+		if i > 0 && reflect.DeepEqual(order, a[i-1]) {
+			t.Fatalf("epochs %d and %d have the same order %v", i-1, i, order)
+		}
+	}
+	if b := orders(SGD{Seed: 4}); !reflect.DeepEqual(a, b) {
+		t.Fatalf("the same seed gave the orders %v and %v", a, b)
+	}
+	if c := orders(SGD{Seed: 5}); reflect.DeepEqual(a, c) {
+		t.Fatal("different seeds gave the same orders")
+	}
+	if d := orders(SGD{Seed: 4, NoShuffle: true}); !reflect.DeepEqual(d[epochs-1], ColumnRange(0, samples-1)) {
+		t.Fatalf("NoShuffle order = %v", d[epochs-1])
+	}
+}
@@ -0,0 +1,29 @@
+package ml
+
+// Identity returns the n x n identity matrix.
+func Identity(n int) [][]float64 {
+	result := Zeros(n, n)
+	for i := range result {
+		result[i][i] = 1.0
+	}
+	return result
+}
+
+// Transpose returns the transpose of A.
+func Transpose(A [][]float64) [][]float64 {
+	if len(A) == 0 {
+		return nil
+	}
+	result := Zeros(len(A[0]), len(A))
+	for r := range A {
+		for c, v := range A[r] {
+			result[c][r] = v
+		}
+	}
+	return result
+}
+
+// Multiply returns the matrix product A B.
+func Multiply(A [][]float64, B [][]float64) [][]float64 {
+	return Predict(A, B)
+}
@@ -0,0 +1,46 @@
+package ml
+
+import (
+	"math"
+)
+
+// MSE returns the mean squared error of every column of predicted against the
+// targets T.
+func MSE(predicted [][]float64, T [][]float64) []float64 {
+	result := make([]float64, len(T[0]))
+	for r := range T {
+		for c := range T[r] {
+			diff := predicted[r][c] - T[r][c]
+			result[c] += diff * diff
+		}
+	}
+	for c := range result {
+		result[c] = result[c] / float64(len(T))
+	}
+	return result
+}
+
+// RMSE returns the root mean squared error of every column of predicted
+// against the targets T.
+func RMSE(predicted [][]float64, T [][]float64) []float64 {
+	result := MSE(predicted, T)
+	for c := range result {
+		result[c] = math.Sqrt(result[c])
+	}
+	return result
+}
+
+// MAE returns the mean absolute error of every column of predicted against the
+// targets T.
+func MAE(predicted [][]float64, T [][]float64) []float64 {
+	result := make([]float64, len(T[0]))
+	for r := range T {
+		for c := range T[r] {
+			result[c] += math.Abs(predicted[r][c] - T[r][c])
+		}
+	}
+	for c := range result {
+		result[c] = result[c] / float64(len(T))
+	}
+	return result
+}
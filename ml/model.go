@@ -0,0 +1,136 @@
+package ml
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+// LinearModel is a trained linear model together with what was fit to
+// prepare its inputs, so that it can be saved and used again on new data.
+type LinearModel struct {
+	//Features are the names of the numeric input columns, in order.
+	Features []string `json:"features,omitempty"`
+	//Targets are the names of the output columns, in order.
+	Targets []string `json:"targets,omitempty"`
+	//Encoders are the fitted encoders of the categorical input columns, their
+	//columns follow the numeric ones.  They are applied by PredictDataset.
+	Encoders []EncodedColumn `json:"encoders,omitempty"`
+	//Bias adds the column of 1's in front of the inputs, as AddBias does,
+	//when they are built by PredictDataset.
+	Bias bool `json:"bias,omitempty"`
+	//Scaler, when set, rescales the inputs before they reach W.
+	Scaler Scaler `json:"-"`
+	//TargetScaler, when set, is the scaler the targets were rescaled with
+	//for training.  Predictions are converted back with it.
+	TargetScaler Scaler `json:"-"`
+
+	W [][]float64 `json:"w"`
+}
+
+// Predict runs the model against every row of X, rescaling X first when the
+// model has a Scaler and converting the predictions back to the units of the
+// targets when it has a TargetScaler.  X already holds the encoded columns,
+// use PredictDataset to encode the categorical columns with the model.
+func (m *LinearModel) Predict(X [][]float64) [][]float64 {
+	if m.Scaler != nil {
+		X = m.Scaler.Transform(X)
+	}
+	predicted := Predict(X, m.W)
+	if m.TargetScaler != nil {
+		predicted = m.TargetScaler.InverseTransform(predicted)
+	}
+	return predicted
+}
+
+// PredictDataset runs the model against every row of d.  The inputs are the
+// Features columns of d followed by the columns of the Encoders, laid out the
+// same way as by Encode, with the bias column in front when Bias is set.
+func (m *LinearModel) PredictDataset(d *Dataset) ([][]float64, error) {
+	features, err := d.SelectNames(m.Features...)
+	if err != nil {
+		return nil, err
+	}
+	X, _, err := Encode(features, m.Encoders)
+	if err != nil {
+		return nil, err
+	}
+	if m.Bias {
+		X = AddBias(X)
+	}
+	if len(X) > 0 && len(X[0]) != len(m.W) {
+		return nil, fmt.Errorf("linear model: %d inputs for %d rows of weights", len(X[0]), len(m.W))
+	}
+	return m.Predict(X), nil
+}
+
+// savedLinearModel is how a LinearModel is written to JSON, with the types of
+// its scalers.
+type savedLinearModel struct {
+	*plainLinearModel
+	Scaler       *savedScaler `json:"scaler,omitempty"`
+	TargetScaler *savedScaler `json:"targetScaler,omitempty"`
+}
+
+type plainLinearModel LinearModel
+
+// MarshalJSON saves the model with the types of its scalers.  It has a value
+// receiver so that a LinearModel is saved the same way as a *LinearModel.
+func (m LinearModel) MarshalJSON() ([]byte, error) {
+	saved := savedLinearModel{plainLinearModel: (*plainLinearModel)(&m)}
+	var err error
+	if m.Scaler != nil {
+		if saved.Scaler, err = marshalScaler(m.Scaler); err != nil {
+			return nil, err
+		}
+	}
+	if m.TargetScaler != nil {
+		if saved.TargetScaler, err = marshalScaler(m.TargetScaler); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(saved)
+}
+
+// UnmarshalJSON reads a model saved by MarshalJSON.
+func (m *LinearModel) UnmarshalJSON(data []byte) error {
+	saved := savedLinearModel{plainLinearModel: (*plainLinearModel)(m)}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return err
+	}
+	m.Scaler, m.TargetScaler = nil, nil
+	var err error
+	if saved.Scaler != nil {
+		if m.Scaler, err = unmarshalScaler(saved.Scaler); err != nil {
+			return err
+		}
+	}
+	if saved.TargetScaler != nil {
+		if m.TargetScaler, err = unmarshalScaler(saved.TargetScaler); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Save writes the model to a JSON file.
+func (m *LinearModel) Save(filename string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, data, 0644)
+}
+
+// LoadLinearModel reads a model written by Save.
+func LoadLinearModel(filename string) (*LinearModel, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	result := &LinearModel{}
+	if err := json.Unmarshal(data, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
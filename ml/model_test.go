@@ -0,0 +1,131 @@
+package ml
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestLinearModelJSON(t *testing.T) {
+	X := [][]float64{{1, 2, 1}, {1, 4, 10}, {1, 6, 100}}
+	scaler := &StandardScaler{Skip: []int{0}}
+	if err := scaler.Fit(X); err != nil {
+		t.Fatal(err)
+	}
+	targetScaler := &ChainScaler{Scalers: []Scaler{&Log1pScaler{}, &MinMaxScaler{}}}
+	if err := targetScaler.Fit([][]float64{{1}, {3}, {7}}); err != nil {
+		t.Fatal(err)
+	}
+	model := LinearModel{
+		Features:     []string{"bias", "a", "b"},
+		Targets:      []string{"t"},
+		Scaler:       scaler,
+		TargetScaler: targetScaler,
+		W:            [][]float64{{0.5}, {0.25}, {-0.1}},
+	}
+	want := model.Predict(X)
+
+	//a value and a pointer have to be saved the same way
+	for name, v := range map[string]interface{}{"value": model, "pointer": &model} {
+		t.Run(name, func(t *testing.T) {
+			data, err := json.Marshal(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var loaded LinearModel
+			if err := json.Unmarshal(data, &loaded); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(loaded.Scaler, model.Scaler) {
+				t.Fatalf("Scaler = %#v, want %#v", loaded.Scaler, model.Scaler)
+			}
+			if _, ok := loaded.TargetScaler.(*ChainScaler); !ok {
+				t.Fatalf("TargetScaler = %#v, want a *ChainScaler", loaded.TargetScaler)
+			}
+			if !reflect.DeepEqual(loaded.Features, model.Features) || !reflect.DeepEqual(loaded.Targets, model.Targets) {
+				t.Fatalf("got features %q and targets %q", loaded.Features, loaded.Targets)
+			}
+			assertClose(t, "Predict", loaded.Predict(X), want, 1e-12)
+		})
+	}
+}
+
+func TestCheckpointJSON(t *testing.T) {
+	checkpoint := Checkpoint{Epoch: 3, W: [][]float64{{1}, {2}}, Optimizer: &Momentum{Momentum: 0.8, Velocity: [][]float64{{0.1}, {0.2}}}}
+	for name, v := range map[string]interface{}{"value": checkpoint, "pointer": &checkpoint} {
+		t.Run(name, func(t *testing.T) {
+			data, err := json.Marshal(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var loaded Checkpoint
+			if err := json.Unmarshal(data, &loaded); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(loaded, checkpoint) {
+				t.Fatalf("got %#v, want %#v", loaded, checkpoint)
+			}
+		})
+	}
+
+	//the state of early stopping, a plateau and an embedded Adam come back too
+	checkpoint = Checkpoint{
+		Epoch:         7,
+		W:             [][]float64{{1}, {2}},
+		Optimizer:     &AdamW{Adam: Adam{Beta1: 0.8, Step: 7, M: [][]float64{{0.1}, {0.2}}, V: [][]float64{{0.01}, {0.04}}}, WeightDecay: 0.01, Skip: []int{0}},
+		EarlyStopping: &EarlyStoppingState{BestEpoch: 4, BestScore: 0.25, Wait: 2, W: [][]float64{{0.5}, {1.5}}},
+		Plateau:       &PlateauState{Scale: 0.25, Best: 0.3, Wait: 1, Cooling: 2},
+	}
+	filename := writeTemp(t, "checkpoint.json", "")
+	if err := checkpoint.Save(filename); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadCheckpoint(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*loaded, checkpoint) {
+		t.Fatalf("got %#v, want %#v", *loaded, checkpoint)
+	}
+}
+
+func TestLinearModelPredictDataset(t *testing.T) {
+	d := &Dataset{
+		Names:         []string{"x", "unused"},
+		Data:          [][]float64{{1, 100}, {2, 100}, {3, 100}},
+		CategoryNames: []string{"type"},
+		Categories:    [][]string{{"red"}, {"white"}, {"rose"}},
+	}
+	encoders := []EncodedColumn{{Column: "type", OneHot: &OneHotEncoder{Categories: []string{"red", "white"}}}}
+	model := LinearModel{
+		Features: []string{"x"},
+		Encoders: encoders,
+		Bias:     true,
+		W:        [][]float64{{1}, {2}, {10}, {20}},
+	}
+
+	//the encoders are saved with the model and used again after loading it
+	data, err := json.Marshal(model)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded LinearModel
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatal(err)
+	}
+	for name, m := range map[string]*LinearModel{"model": &model, "loaded": &loaded} {
+		got, err := m.PredictDataset(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		//bias, x, type=red, type=white, an unknown type adds nothing
+		assertClose(t, name, got, [][]float64{{1 + 2 + 10}, {1 + 4 + 20}, {1 + 6}}, 0)
+	}
+
+	model.Bias = false
+	_, err = model.PredictDataset(d)
+	assertErrorContains(t, err, "3 inputs for 4 rows of weights")
+	model.Features = []string{"z"}
+	_, err = model.PredictDataset(d)
+	assertErrorContains(t, err, `"z"`)
+}
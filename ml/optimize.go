@@ -0,0 +1,261 @@
+package ml
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// Optimizer decides how a step of gradient descent changes the weights.  The
+// adaptive optimizers keep state between steps, such as a running average of
+// the gradient, in exported fields so that it can be saved in a Checkpoint
+// and training picked up where it left off.  An optimizer must only be used
+// for one set of weights at a time.
+type Optimizer interface {
+	//Update moves the weights w against the gradient of the loss at the given
+	//learning rate.  gradient has the same shape as w.
+	Update(w *Dense, gradient *Dense, rate float64)
+}
+
+// GradientDescent is plain gradient descent, w = w - rate * gradient.  It is
+// what SGD uses when no Optimizer is set.
+type GradientDescent struct{}
+
+// Update takes a step against the gradient.
+func (o *GradientDescent) Update(w *Dense, gradient *Dense, rate float64) {
+	w.AddScaled(w, -rate, gradient)
+}
+
+// Momentum keeps a running sum of the gradients so that steps keep moving in
+// a consistent direction and speed up through long shallow valleys, which
+// helps when the features have very different scales.
+type Momentum struct {
+	//Momentum is how much of the previous velocity is kept, it defaults to
+	//0.9.
+	Momentum float64 `json:"momentum"`
+	//Nesterov looks ahead along the velocity before taking the step
+	//(Nesterov accelerated gradient).
+	Nesterov bool `json:"nesterov,omitempty"`
+
+	Velocity [][]float64 `json:"velocity"`
+}
+
+// Update takes a step along the velocity.
+func (o *Momentum) Update(w *Dense, gradient *Dense, rate float64) {
+	mu := defaultValue(o.Momentum, 0.9)
+	o.Velocity = optimizerState(o.Velocity, w)
+	for r := range o.Velocity {
+		wRow, gRow, vRow := w.Row(r), gradient.Row(r), o.Velocity[r]
+		for c, g := range gRow {
+			vRow[c] = mu*vRow[c] + g
+			if o.Nesterov {
+				wRow[c] -= rate * (g + mu*vRow[c])
+			} else {
+				wRow[c] -= rate * vRow[c]
+			}
+		}
+	}
+}
+
+// AdaGrad divides the step for every weight by the square root of the sum of
+// all of its squared gradients, so weights that have had large gradients
+// take smaller steps.  The steps only ever get smaller.
+type AdaGrad struct {
+	//Epsilon avoids dividing by zero, it defaults to 1e-8.
+	Epsilon float64 `json:"epsilon"`
+
+	Sums [][]float64 `json:"sums"`
+}
+
+// Update takes a step scaled by the gradients seen so far.
+func (o *AdaGrad) Update(w *Dense, gradient *Dense, rate float64) {
+	epsilon := defaultValue(o.Epsilon, 1e-8)
+	o.Sums = optimizerState(o.Sums, w)
+	for r := range o.Sums {
+		wRow, gRow, sRow := w.Row(r), gradient.Row(r), o.Sums[r]
+		for c, g := range gRow {
+			sRow[c] += g * g
+			wRow[c] -= rate * g / (math.Sqrt(sRow[c]) + epsilon)
+		}
+	}
+}
+
+// RMSProp is AdaGrad with a decaying average of the squared gradients in
+// place of their sum, so the steps can grow again.
+type RMSProp struct {
+	//Decay is how much of the previous average is kept, it defaults to 0.9.
+	Decay float64 `json:"decay"`
+	//Epsilon avoids dividing by zero, it defaults to 1e-8.
+	Epsilon float64 `json:"epsilon"`
+
+	MeanSquares [][]float64 `json:"meanSquares"`
+}
+
+// Update takes a step scaled by the recent gradients.
+func (o *RMSProp) Update(w *Dense, gradient *Dense, rate float64) {
+	rho := defaultValue(o.Decay, 0.9)
+	epsilon := defaultValue(o.Epsilon, 1e-8)
+	o.MeanSquares = optimizerState(o.MeanSquares, w)
+	for r := range o.MeanSquares {
+		wRow, gRow, mRow := w.Row(r), gradient.Row(r), o.MeanSquares[r]
+		for c, g := range gRow {
+			mRow[c] = rho*mRow[c] + (1-rho)*g*g
+			wRow[c] -= rate * g / (math.Sqrt(mRow[c]) + epsilon)
+		}
+	}
+}
+
+// Adam combines momentum with RMSProp, keeping decaying averages of both the
+// gradients and the squared gradients, corrected for starting at zero.
+type Adam struct {
+	//Beta1 is the decay of the average gradient, it defaults to 0.9.
+	Beta1 float64 `json:"beta1"`
+	//Beta2 is the decay of the average squared gradient, it defaults to
+	//0.999.
+	Beta2 float64 `json:"beta2"`
+	//Epsilon avoids dividing by zero, it defaults to 1e-8.
+	Epsilon float64 `json:"epsilon"`
+
+	//Step is the number of updates made so far.
+	Step int         `json:"step"`
+	M    [][]float64 `json:"m"`
+	V    [][]float64 `json:"v"`
+}
+
+// Update takes a step along the average gradient.
+func (o *Adam) Update(w *Dense, gradient *Dense, rate float64) {
+	beta1 := defaultValue(o.Beta1, 0.9)
+	beta2 := defaultValue(o.Beta2, 0.999)
+	epsilon := defaultValue(o.Epsilon, 1e-8)
+	o.M = optimizerState(o.M, w)
+	o.V = optimizerState(o.V, w)
+	o.Step++
+
+	//the averages start at zero, so early on they are divided up to their real size
+	correction1 := 1 - math.Pow(beta1, float64(o.Step))
+	correction2 := 1 - math.Pow(beta2, float64(o.Step))
+	for r := range o.M {
+		wRow, gRow, mRow, vRow := w.Row(r), gradient.Row(r), o.M[r], o.V[r]
+		for c, g := range gRow {
+			mRow[c] = beta1*mRow[c] + (1-beta1)*g
+			vRow[c] = beta2*vRow[c] + (1-beta2)*g*g
+			wRow[c] -= rate * (mRow[c] / correction1) / (math.Sqrt(vRow[c]/correction2) + epsilon)
+		}
+	}
+}
+
+// AdamW is Adam with weight decay that shrinks the weights toward zero
+// directly, instead of through the gradient where Adam's scaling would weaken
+// it.
+type AdamW struct {
+	Adam
+	//WeightDecay is the fraction of each weight, times the learning rate,
+	//taken away on every step.
+	WeightDecay float64 `json:"weightDecay"`
+	//Skip are the rows of w that are not decayed, such as row 0 for the bias.
+	//SGD returns an error before training when one is not a row of w.
+	Skip []int `json:"skip,omitempty"`
+}
+
+// Update decays the weights and then takes an Adam step.
+func (o *AdamW) Update(w *Dense, gradient *Dense, rate float64) {
+	rows, _ := w.Dims()
+	skip := skipped(rows, o.Skip)
+	for r := 0; r < rows; r++ {
+		if skip[r] {
+			continue
+		}
+		wRow := w.Row(r)
+		for c := range wRow {
+			wRow[c] -= rate * o.WeightDecay * wRow[c]
+		}
+	}
+	o.Adam.Update(w, gradient, rate)
+}
+
+// checkOptimizer makes sure that o can update weights shaped like w, which
+// Update has no way to report.
+func checkOptimizer(o Optimizer, w *Dense) error {
+	if adamW, ok := o.(*AdamW); ok {
+		rows, _ := w.Dims()
+		for _, r := range adamW.Skip {
+			if r < 0 || r >= rows {
+				return fmt.Errorf("adamw optimizer: skip row %d is out of range of %d rows of weights", r, rows)
+			}
+		}
+	}
+	return nil
+}
+
+// defaultValue returns v, or def when v is not set.
+func defaultValue(v float64, def float64) float64 {
+	if v == 0 {
+		return def
+	}
+	return v
+}
+
+// optimizerState returns state when it already matches the shape of w, such
+// as after loading a checkpoint, or else a new matrix of zeros.
+func optimizerState(state [][]float64, w *Dense) [][]float64 {
+	rows, cols := w.Dims()
+	if len(state) == rows && (rows == 0 || len(state[0]) == cols) {
+		return state
+	}
+	return Zeros(rows, cols)
+}
+
+// savedOptimizer is how an Optimizer is written to JSON, with the name of its
+// type so that it can be read back.
+type savedOptimizer struct {
+	Type      string          `json:"type"`
+	Optimizer json.RawMessage `json:"optimizer"`
+}
+
+// optimizerTypes returns a new optimizer for every type name used in saved
+// files.
+var optimizerTypes = map[string]func() Optimizer{
+	"gradientDescent": func() Optimizer { return &GradientDescent{} },
+	"momentum":        func() Optimizer { return &Momentum{} },
+	"adaGrad":         func() Optimizer { return &AdaGrad{} },
+	"rmsProp":         func() Optimizer { return &RMSProp{} },
+	"adam":            func() Optimizer { return &Adam{} },
+	"adamW":           func() Optimizer { return &AdamW{} },
+}
+
+func marshalOptimizer(o Optimizer) (*savedOptimizer, error) {
+	var name string
+	switch o.(type) {
+	case *GradientDescent:
+		name = "gradientDescent"
+	case *Momentum:
+		name = "momentum"
+	case *AdaGrad:
+		name = "adaGrad"
+	case *RMSProp:
+		name = "rmsProp"
+	case *Adam:
+		name = "adam"
+	case *AdamW:
+		name = "adamW"
+	default:
+		return nil, fmt.Errorf("can't save optimizer of type %T", o)
+	}
+	data, err := json.Marshal(o)
+	if err != nil {
+		return nil, err
+	}
+	return &savedOptimizer{Type: name, Optimizer: data}, nil
+}
+
+func unmarshalOptimizer(saved *savedOptimizer) (Optimizer, error) {
+	newOptimizer, ok := optimizerTypes[saved.Type]
+	if !ok {
+		return nil, fmt.Errorf("unknown optimizer type %q", saved.Type)
+	}
+	result := newOptimizer()
+	if err := json.Unmarshal(saved.Optimizer, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,68 @@
+package ml
+
+import (
+	"math"
+	"testing"
+)
+
+func TestOptimizerSteps(t *testing.T) {
+	//two steps from w = [1 2] at rate 0.1, the gradient changes sign for the
+	//second step of Adam and AdamW
+	first := [][]float64{{0.5}, {-1}}
+	tests := []struct {
+		name      string
+		optimizer Optimizer
+		second    [][]float64
+		want      [][][]float64
+	}{
+		{"gradient descent", &GradientDescent{}, first, [][][]float64{{{0.95}, {2.1}}, {{0.9}, {2.2}}}},
+		//v = [0.5 -1] then [0.75 -1.5]
+		{"momentum", &Momentum{Momentum: 0.5}, first, [][][]float64{{{0.95}, {2.1}}, {{0.875}, {2.25}}}},
+		//steps along g + 0.5 v
+		{"nesterov", &Momentum{Momentum: 0.5, Nesterov: true}, first, [][][]float64{{{0.925}, {2.15}}, {{0.8375}, {2.325}}}},
+		//the sums are g^2 and then 2 g^2
+		{"adagrad", &AdaGrad{}, first, [][][]float64{{{0.9}, {2.1}}, {{0.9 - 0.1/math.Sqrt(2)}, {2.1 + 0.1/math.Sqrt(2)}}}},
+		//the averages are g^2 / 2 and then 3 g^2 / 4
+		{"rmsprop", &RMSProp{Decay: 0.5}, first, [][][]float64{
+			{{1 - 0.1*math.Sqrt(2)}, {2 + 0.1*math.Sqrt(2)}},
+			{{1 - 0.1*math.Sqrt(2) - 0.2/math.Sqrt(3)}, {2 + 0.1*math.Sqrt(2) + 0.2/math.Sqrt(3)}},
+		}},
+		//the first corrected step is the rate times the sign of g, after the
+		//sign changes the corrected average gradient is -g / 19
+		{"adam", &Adam{}, [][]float64{{-0.5}, {1}}, [][][]float64{{{0.9}, {2.1}}, {{0.9 + 0.1/19}, {2.1 - 0.1/19}}}},
+		//row 1 loses 0.1 * 0.5 of itself before each step, row 0 is skipped
+		{"adamw", &AdamW{WeightDecay: 0.5, Skip: []int{0}}, [][]float64{{-0.5}, {1}}, [][][]float64{{{0.9}, {2}}, {{0.9 + 0.1/19}, {1.9 - 0.1/19}}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := DenseOf([][]float64{{1}, {2}})
+			for i, gradient := range [][][]float64{first, test.second} {
+				test.optimizer.Update(w, DenseOf(gradient), 0.1)
+				assertClose(t, "w", w.Slices(), test.want[i], 1e-7)
+			}
+		})
+	}
+}
+
+func TestAdamStep(t *testing.T) {
+	o := &AdamW{}
+	w := NewDense(2, 1, nil)
+	o.Update(w, DenseOf([][]float64{{1}, {1}}), 0.1)
+	o.Update(w, DenseOf([][]float64{{1}, {1}}), 0.1)
+	if o.Step != 2 {
+		t.Fatalf("Step = %d, want 2", o.Step)
+	}
+	assertClose(t, "M", o.M, [][]float64{{0.19}, {0.19}}, 1e-12)
+	assertClose(t, "V", o.V, [][]float64{{0.001999}, {0.001999}}, 1e-12)
+}
+
+func TestAdamWSkipOutOfRange(t *testing.T) {
+	X, T := line()
+	for _, skip := range []int{-1, 3} {
+		s := SGD{LearningRate: 0.01, Epochs: 1, Optimizer: &AdamW{WeightDecay: 0.1, Skip: []int{0, skip}}}
+		_, err := s.Train(X, T, Zeros(3, 1))
+		assertErrorContains(t, err, "adamw optimizer: skip row")
+		err = s.TrainCSR(CSROf(X), DenseOf(T), NewDense(3, 1, nil))
+		assertErrorContains(t, err, "out of range of 3 rows of weights")
+	}
+}
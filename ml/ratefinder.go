@@ -0,0 +1,198 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+)
+
+// RateFinder runs a learning rate range test, a short run of SGD that starts
+// from a tiny learning rate and makes it larger after every step, recording
+// the loss as it goes.  At first the rate is too small for the loss to move,
+// then it falls quickly, and once the rate is too large it blows up.  The
+// suggested Rate is where the loss starts falling fastest.
+//
+// The test trains a copy of the weights and the optimizer, using the batch
+// size and order of the SGD, so the run that follows is not affected.  The
+// optimizer is copied by saving it, so only the optimizers of this package
+// can be used, the test returns an error for any other.
+type RateFinder struct {
+	//MinRate and MaxRate are the first and last rates of the sweep, the rate
+	//grows by the same factor every step.  They default to 1e-8 and 10.
+	MinRate float64
+	MaxRate float64
+	//Steps is the number of batches in the sweep, it defaults to an epoch
+	//but no fewer than 100.
+	Steps int
+	//Smoothing is the weight of the moving average that smooths the loss, it
+	//defaults to 0.98.
+	Smoothing float64
+	//StopFactor stops the sweep once the smoothed loss is this many times the
+	//lowest seen so far, it defaults to 4.
+	StopFactor float64
+
+	//Rates and Losses are the loss curve, the rate of every step and the
+	//smoothed mean squared error of its batch.  Rate is the suggested rate.
+	Rates  []float64
+	Losses []float64
+	Rate   float64
+}
+
+// Find runs the test over X and T starting from the weights w, and returns
+// the suggested rate.  w is not modified.
+func (f *RateFinder) Find(s SGD, X [][]float64, T [][]float64, w [][]float64) (float64, error) {
+	if err := f.FindDense(s, DenseOf(X), DenseOf(T), DenseOf(w)); err != nil {
+		return 0, err
+	}
+	return f.Rate, nil
+}
+
+// FindDense runs the test over X and T starting from the weights w.  w is not
+// modified.
+func (f *RateFinder) FindDense(s SGD, X *Dense, T *Dense, w *Dense) error {
+	samples, inputs := X.Dims()
+	_, outputs := w.Dims()
+	batchSize := s.batchSize(samples)
+	xBatch := NewDense(batchSize, inputs, nil)
+	errBatch := NewDense(batchSize, outputs, nil)
+	return f.find(s, samples, w, func(rows []int, w *Dense, gradient *Dense) float64 {
+		xb := xBatch.Slice(0, len(rows), 0, inputs)
+		eb := errBatch.Slice(0, len(rows), 0, outputs)
+		for r, i := range rows {
+			copy(xb.Row(r), X.Row(i))
+		}
+		eb.Mul(xb, w)
+		for r, i := range rows {
+			eRow := eb.Row(r)
+			for c, t := range T.Row(i) {
+				eRow[c] = t - eRow[c]
+			}
+		}
+		gradient.MulTransA(xb, eb)
+		gradient.Scale(-1/float64(len(rows)), gradient)
+		return meanSquare(eb)
+	})
+}
+
+// FindCSR runs the test over the sparse inputs X and the targets T starting
+// from the weights w.  w is not modified.
+func (f *RateFinder) FindCSR(s SGD, X *CSR, T *Dense, w *Dense) error {
+	samples, _ := X.Dims()
+	_, outputs := w.Dims()
+	errBatch := NewDense(s.batchSize(samples), outputs, nil)
+	return f.find(s, samples, w, func(rows []int, w *Dense, gradient *Dense) float64 {
+		xb := X.Rows(rows)
+		eb := errBatch.Slice(0, len(rows), 0, outputs)
+		eb.MulCSR(xb, w)
+		for r, i := range rows {
+			eRow := eb.Row(r)
+			for c, t := range T.Row(i) {
+				eRow[c] = t - eRow[c]
+			}
+		}
+		gradient.MulCSC(xb.T(), eb)
+		gradient.Scale(-1/float64(len(rows)), gradient)
+		return meanSquare(eb)
+	})
+}
+
+// find sweeps the rate over steps of batches of samples.  batch sets the
+// gradient of the rows of a batch for the weights w and returns their mean
+// squared error.
+func (f *RateFinder) find(s SGD, samples int, w *Dense, batch func(rows []int, w *Dense, gradient *Dense) float64) error {
+	minRate := defaultValue(f.MinRate, 1e-8)
+	maxRate := defaultValue(f.MaxRate, 10)
+	smoothing := defaultValue(f.Smoothing, 0.98)
+	stopFactor := defaultValue(f.StopFactor, 4)
+	batchSize := s.batchSize(samples)
+	steps := f.Steps
+	if steps <= 0 {
+		steps = (samples + batchSize - 1) / batchSize
+		if steps < 100 {
+			steps = 100
+		}
+	}
+	if minRate <= 0 || minRate >= maxRate {
+		return fmt.Errorf("rate finder: can't sweep from %v to %v", minRate, maxRate)
+	}
+	if samples == 0 {
+		return fmt.Errorf("rate finder: no samples")
+	}
+
+	//train copies so that w and the optimizer are left as they were
+	weights := NewDense(w.rows, w.cols, nil)
+	weights.Copy(w)
+	optimizer, err := copyOptimizer(s.optimizer())
+	if err != nil {
+		return fmt.Errorf("rate finder: can't copy optimizer of type %T, only the optimizers of this package can be copied", s.optimizer())
+	}
+	gradient := NewDense(w.rows, w.cols, nil)
+	s.first = 0
+	order, shuffle := s.order(samples)
+
+	f.Rates, f.Losses, f.Rate = nil, nil, 0
+	growth := 1.0
+	if steps > 1 {
+		growth = math.Pow(maxRate/minRate, 1/float64(steps-1))
+	}
+	average, lowest := 0.0, math.Inf(1)
+	for i, start := 0, samples; i < steps; i, start = i+1, start+batchSize {
+		//start a new epoch once every sample has been seen
+		if start >= samples {
+			shuffle()
+			start = 0
+		}
+		end := start + batchSize
+		if end > samples {
+			end = samples
+		}
+		rate := minRate * math.Pow(growth, float64(i))
+
+		loss := batch(order[start:end], weights, gradient)
+		s.addL2(gradient, weights)
+		if math.IsNaN(loss) || math.IsInf(loss, 0) {
+			break
+		}
+		//the moving average starts at zero, dividing corrects for that
+		average = smoothing*average + (1-smoothing)*loss
+		smoothed := average / (1 - math.Pow(smoothing, float64(i+1)))
+		if smoothed > stopFactor*lowest {
+			break
+		}
+		lowest = math.Min(lowest, smoothed)
+		f.Rates = append(f.Rates, rate)
+		f.Losses = append(f.Losses, smoothed)
+
+		optimizer.Update(weights, gradient, rate)
+	}
+
+	//suggest the rate at the start of the stretch of a twentieth of the steps
+	//where the loss fell the most, leaving out the first tenth of the steps
+	//while the average settles
+	window := steps / 20
+	if window < 1 {
+		window = 1
+	}
+	steepest := 0.0
+	for i := steps/10 + window; i < len(f.Losses); i++ {
+		if drop := f.Losses[i] - f.Losses[i-window]; drop < steepest {
+			steepest = drop
+			f.Rate = f.Rates[i-window]
+		}
+	}
+	if f.Rate == 0 {
+		return fmt.Errorf("rate finder: the loss did not fall between %v and %v", minRate, maxRate)
+	}
+	return nil
+}
+
+// meanSquare returns the mean of the squares of the values of d.
+func meanSquare(d *Dense) float64 {
+	rows, cols := d.Dims()
+	sum := 0.0
+	for r := 0; r < rows; r++ {
+		for _, v := range d.Row(r) {
+			sum += v * v
+		}
+	}
+	return sum / float64(rows*cols)
+}
@@ -0,0 +1,88 @@
+package ml
+
+import (
+	"math"
+	"testing"
+)
+
+// customOptimizer is an optimizer from outside of the package.
+type customOptimizer struct{}
+
+func (o *customOptimizer) Update(w *Dense, gradient *Dense, rate float64) {
+	w.Sub(w, gradient)
+}
+
+func TestRateFinder(t *testing.T) {
+	X, T := line()
+	adam := &Adam{}
+	f := &RateFinder{}
+	rate, err := f.Find(SGD{BatchSize: 4, Seed: 1, Optimizer: adam}, X, T, Zeros(3, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rate <= 1e-8 || rate >= 10 {
+		t.Fatalf("suggested rate %v, want one inside the sweep", rate)
+	}
+	if adam.M != nil {
+		t.Fatal("the optimizer of the SGD was modified")
+	}
+}
+
+func TestRateFinderCustomOptimizer(t *testing.T) {
+	X, T := line()
+	_, err := (&RateFinder{}).Find(SGD{Optimizer: &customOptimizer{}}, X, T, Zeros(3, 1))
+	assertErrorContains(t, err, "can't copy optimizer of type *ml.customOptimizer")
+}
+
+func TestRateFinderSweep(t *testing.T) {
+	X, T := line()
+	tests := []struct {
+		name     string
+		finder   *RateFinder
+		diverges bool
+	}{
+		//plain gradient descent blows up well before a rate of 10
+		{"stops on divergence", &RateFinder{Steps: 200}, true},
+		//rates up to 0.5 are all stable for per-sample steps
+		{"whole sweep", &RateFinder{MinRate: 1e-4, MaxRate: 0.5, Steps: 100}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := test.finder
+			w := Zeros(3, 1)
+			if _, err := f.Find(SGD{Seed: 1}, X, T, w); err != nil {
+				t.Fatal(err)
+			}
+			assertClose(t, "w", w, Zeros(3, 1), 0)
+			minRate, maxRate := defaultValue(f.MinRate, 1e-8), defaultValue(f.MaxRate, 10)
+
+			//every rate is the same factor larger than the one before
+			growth := math.Pow(maxRate/minRate, 1/float64(f.Steps-1))
+			if len(f.Rates) == 0 || f.Rates[0] != minRate || len(f.Losses) != len(f.Rates) {
+				t.Fatalf("%d rates starting at %v and %d losses, want them to start at %v", len(f.Rates), f.Rates, len(f.Losses), minRate)
+			}
+			for i := 1; i < len(f.Rates); i++ {
+				if ratio := f.Rates[i] / f.Rates[i-1]; math.Abs(ratio-growth) > 1e-9*growth {
+					t.Fatalf("rate %d is %v times the one before, want %v", i, ratio, growth)
+				}
+			}
+
+			last := f.Rates[len(f.Rates)-1]
+			if test.diverges && (len(f.Rates) >= f.Steps || last >= maxRate) {
+				t.Fatalf("swept %d of %d steps up to %v, want to stop before %v", len(f.Rates), f.Steps, last, maxRate)
+			}
+			if !test.diverges && (len(f.Rates) != f.Steps || math.Abs(last-maxRate) > 1e-9*maxRate) {
+				t.Fatalf("swept %d of %d steps up to %v, want all of them up to %v", len(f.Rates), f.Steps, last, maxRate)
+			}
+
+			//the suggestion is one of the rates that was swept
+			found := false
+			for _, rate := range f.Rates {
+				found = found || rate == f.Rate
+			}
+			if !found || f.Rate < minRate || f.Rate > last {
+				t.Fatalf("suggested rate %v, want one of the rates from %v to %v", f.Rate, minRate, last)
+			}
+		})
+	}
+}
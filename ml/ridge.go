@@ -0,0 +1,119 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+)
+
+// Ridge returns the weights w that minimize the mean squared error of X w
+// against T plus alpha times the sum of the squared weights, solved directly.
+// The first row of w, the bias added by AddBias, is not penalized.  alpha
+// means the same as SGD.L2, so both find the same weights, and with an alpha
+// of 0 it is LeastSquares.
+//
+// The penalty is added as extra rows of X, a row of sqrt(n alpha) for every
+// weight, with targets of zero, and the result is solved with a QR
+// decomposition.  Because of them X can have more columns than rows, or
+// columns that depend on each other.
+func Ridge(X [][]float64, T [][]float64, alpha float64) ([][]float64, error) {
+	if alpha < 0 {
+		return nil, fmt.Errorf("ridge: alpha %v is negative", alpha)
+	}
+	if alpha == 0 || len(X) == 0 {
+		return LeastSquares(X, T)
+	}
+	inputs := len(X[0])
+	outputs := len(T[0])
+	penalty := math.Sqrt(float64(len(X)) * alpha)
+	A := append([][]float64(nil), X...)
+	B := append([][]float64(nil), T...)
+	for j := 1; j < inputs; j++ {
+		row := make([]float64, inputs)
+		row[j] = penalty
+		A = append(A, row)
+		B = append(B, make([]float64, outputs))
+	}
+	return LeastSquares(A, B)
+}
+
+// RidgeRegression is a linear model fit with Ridge.  It implements Model.
+type RidgeRegression struct {
+	Alpha float64
+	W     [][]float64
+}
+
+// Fit finds the weights for X and T.
+func (m *RidgeRegression) Fit(X [][]float64, T [][]float64) error {
+	w, err := Ridge(X, T, m.Alpha)
+	if err != nil {
+		return err
+	}
+	m.W = w
+	return nil
+}
+
+// Predict runs the weights against every row of X.
+func (m *RidgeRegression) Predict(X [][]float64) [][]float64 {
+	return Predict(X, m.W)
+}
+
+// LogSpace returns n values from min to max spaced evenly on a log scale,
+// such as the alphas of a regularization path.
+func LogSpace(min float64, max float64, n int) []float64 {
+	result := make([]float64, n)
+	for i := range result {
+		if n == 1 {
+			result[i] = min
+			continue
+		}
+		result[i] = min * math.Pow(max/min, float64(i)/float64(n-1))
+	}
+	return result
+}
+
+// PathPoint is the model trained with one alpha of a regularization path,
+// with the error of each output column on the training and the validation
+// data.
+type PathPoint struct {
+	Alpha          float64
+	Model          Model
+	TrainRMSE      []float64
+	ValidationRMSE []float64
+}
+
+// RegularizationPath trains a new model from newModel on X and T for every
+// alpha, and measures its error on validationX and validationT.  The points
+// come back in the order of alphas.
+func RegularizationPath(newModel func(alpha float64) Model, alphas []float64, X [][]float64, T [][]float64, validationX [][]float64, validationT [][]float64) ([]PathPoint, error) {
+	if len(alphas) == 0 {
+		return nil, fmt.Errorf("regularization path: no alphas")
+	}
+	result := make([]PathPoint, len(alphas))
+	for i, alpha := range alphas {
+		model := newModel(alpha)
+		if err := model.Fit(X, T); err != nil {
+			return nil, fmt.Errorf("regularization path: alpha %v: %v", alpha, err)
+		}
+		result[i] = PathPoint{
+			Alpha:          alpha,
+			Model:          model,
+			TrainRMSE:      RMSE(model.Predict(X), T),
+			ValidationRMSE: RMSE(model.Predict(validationX), validationT),
+		}
+	}
+	return result, nil
+}
+
+// BestPoint returns the point of a path with the lowest mean validation
+// error.  When several are equal it picks the one with the largest alpha,
+// the simplest model.
+func BestPoint(path []PathPoint) PathPoint {
+	best := path[0]
+	for _, p := range path[1:] {
+		score, bestScore := mean(p.ValidationRMSE), mean(best.ValidationRMSE)
+		if score < bestScore || score == bestScore && p.Alpha > best.Alpha {
+			best = p
+		}
+	}
+	return best
+}
@@ -0,0 +1,104 @@
+package ml
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestRidgeZeroAlpha(t *testing.T) {
+	X, T := noisyLine(1)
+	want, err := LeastSquares(X, T)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := Ridge(X, T, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "alpha 0", w, want, 0)
+
+	//a tiny alpha barely moves the weights
+	w, err = Ridge(X, T, 1e-12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "alpha 1e-12", w, want, 1e-9)
+
+	_, err = Ridge(X, T, -1)
+	assertErrorContains(t, err, "ridge: alpha -1 is negative")
+}
+
+func TestRidgeNormalEquations(t *testing.T) {
+	//more columns than rows, which only the penalty makes solvable
+	rng := rand.New(rand.NewSource(1))
+	X := AddBias(randomMatrix(rng, 4, 6))
+	T := randomMatrix(rng, 4, 2)
+	const alpha = 0.3
+	w, err := Ridge(X, T, alpha)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//(X^T X + n alpha D) w = X^T T, where D leaves out the bias
+	xt := DenseOf(X).T().Slices()
+	left := naiveMul(naiveMul(xt, X), w)
+	for j := 1; j < len(w); j++ {
+		for c := range w[j] {
+			left[j][c] += float64(len(X)) * alpha * w[j][c]
+		}
+	}
+	assertClose(t, "normal equations", left, naiveMul(xt, T), 1e-10)
+}
+
+func TestRidgeMatchesSGD(t *testing.T) {
+	X, T := noisyLine(1)
+	for _, alpha := range []float64{0.1, 1} {
+		want, err := Ridge(X, T, alpha)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := SGD{LearningRate: 0.1, Epochs: 5000, BatchSize: FullBatch, L2: alpha}
+		w, err := s.Train(X, T, Zeros(3, 1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertClose(t, "SGD with L2", w, want, 1e-8)
+	}
+}
+
+func TestBestPoint(t *testing.T) {
+	path := []PathPoint{
+		{Alpha: 0.01, ValidationRMSE: []float64{0.5, 0.7}},
+		{Alpha: 0.1, ValidationRMSE: []float64{0.4, 0.6}},
+		{Alpha: 1, ValidationRMSE: []float64{0.3, 0.9}},
+	}
+	if best := BestPoint(path); best.Alpha != 0.1 {
+		t.Fatalf("BestPoint picked alpha %v, want 0.1 with the lowest mean", best.Alpha)
+	}
+	//a tie goes to the larger alpha
+	path[2].ValidationRMSE = []float64{0.6, 0.4}
+	if best := BestPoint(path); best.Alpha != 1 {
+		t.Fatalf("BestPoint picked alpha %v, want 1 from a tie", best.Alpha)
+	}
+
+	X, T := noisyLine(1)
+	validationX, validationT := noisyLine(2)
+	alphas := LogSpace(1e-4, 10, 9)
+	points, err := RegularizationPath(func(alpha float64) Model { return &RidgeRegression{Alpha: alpha} }, alphas, X, T, validationX, validationT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	best := BestPoint(points)
+	for i, p := range points {
+		if p.Alpha != alphas[i] {
+			t.Fatalf("point %d has alpha %v, want %v", i, p.Alpha, alphas[i])
+		}
+		if p.ValidationRMSE[0] < best.ValidationRMSE[0] {
+			t.Fatalf("alpha %v has validation RMSE %v, lower than %v of the best point", p.Alpha, p.ValidationRMSE[0], best.ValidationRMSE[0])
+		}
+	}
+	if math.Abs(best.ValidationRMSE[0]-RMSE(best.Model.Predict(validationX), validationT)[0]) > 1e-12 {
+		t.Fatal("the best point's validation RMSE is not that of its model")
+	}
+}
@@ -0,0 +1,178 @@
+package ml
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Scaler rescales the columns of a matrix.  A scaler should be fit on the
+// training data only and then used to transform both the training and the
+// test data.
+type Scaler interface {
+	Fit(X [][]float64) error
+	Transform(X [][]float64) [][]float64
+	InverseTransform(X [][]float64) [][]float64
+}
+
+// FitTransform fits s to X and returns X transformed.
+func FitTransform(s Scaler, X [][]float64) ([][]float64, error) {
+	if err := s.Fit(X); err != nil {
+		return nil, err
+	}
+	return s.Transform(X), nil
+}
+
+// StandardScaler converts columns to z-scores, (x - mean) / std.
+//
+// A column with a standard deviation of zero, such as the bias column of 1's,
+// can't be divided by its standard deviation, so it is left unchanged.
+type StandardScaler struct {
+	//Skip are the columns that are left unchanged.
+	Skip []int `json:"skip,omitempty"`
+
+	//Means and Stds are the fitted mean and standard deviation of every column.
+	Means []float64 `json:"means"`
+	Stds  []float64 `json:"stds"`
+}
+
+// Fit finds the mean and standard deviation of every column of X.
+func (s *StandardScaler) Fit(X [][]float64) error {
+	if len(X) == 0 {
+		return fmt.Errorf("standard scaler: no data to fit")
+	}
+	if err := checkSkip(s.Skip, len(X[0])); err != nil {
+		return fmt.Errorf("standard scaler: %v", err)
+	}
+	s.Means = MeanByColumn(X)
+	s.Stds = StdDevByColumn(X)
+	return nil
+}
+
+// Transform returns a standardized copy of X.
+func (s *StandardScaler) Transform(X [][]float64) [][]float64 {
+	return transformColumns(X, s.skipped(), func(c int, v float64) float64 {
+		return (v - s.Means[c]) / s.Stds[c]
+	})
+}
+
+// InverseTransform converts standardized data back to the original units.
+func (s *StandardScaler) InverseTransform(X [][]float64) [][]float64 {
+	return transformColumns(X, s.skipped(), func(c int, v float64) float64 {
+		return v*s.Stds[c] + s.Means[c]
+	})
+}
+
+// FitTransform fits the scaler to X and returns X standardized.
+func (s *StandardScaler) FitTransform(X [][]float64) ([][]float64, error) {
+	return FitTransform(s, X)
+}
+
+func (s *StandardScaler) skipped() []bool {
+	return skipped(len(s.Stds), s.Skip, s.Stds)
+}
+
+// checkSkip makes sure that every column in skip is one of the columns.
+func checkSkip(skip []int, columns int) error {
+	for _, c := range skip {
+		if c < 0 || c >= columns {
+			return fmt.Errorf("skip column %d is out of range of %d columns", c, columns)
+		}
+	}
+	return nil
+}
+
+// skipped returns whether each of the columns is left unchanged, which is the
+// case for the columns listed in skip and the columns with a zero in any of
+// scales.
+func skipped(columns int, skip []int, scales ...[]float64) []bool {
+	result := make([]bool, columns)
+	for _, c := range skip {
+		result[c] = true
+	}
+	for _, scale := range scales {
+		for c, v := range scale {
+			if v == 0 {
+				result[c] = true
+			}
+		}
+	}
+	return result
+}
+
+// transformColumns returns a copy of X with f applied to every value of the
+// columns that are not skipped.
+func transformColumns(X [][]float64, skip []bool, f func(c int, v float64) float64) [][]float64 {
+	result := make([][]float64, len(X))
+	for r := range X {
+		rowData := make([]float64, len(X[r]))
+		for c, v := range X[r] {
+			if c < len(skip) && skip[c] {
+				rowData[c] = v
+			} else {
+				rowData[c] = f(c, v)
+			}
+		}
+		result[r] = rowData
+	}
+	return result
+}
+
+// savedScaler is how a Scaler is written to JSON, with the name of its type so
+// that it can be read back.
+type savedScaler struct {
+	Type   string          `json:"type"`
+	Scaler json.RawMessage `json:"scaler"`
+}
+
+// scalerTypes returns a new scaler for every type name used in saved files.
+var scalerTypes = map[string]func() Scaler{
+	"standard":   func() Scaler { return &StandardScaler{} },
+	"minMax":     func() Scaler { return &MinMaxScaler{} },
+	"robust":     func() Scaler { return &RobustScaler{} },
+	"maxAbs":     func() Scaler { return &MaxAbsScaler{} },
+	"log1p":      func() Scaler { return &Log1pScaler{} },
+	"boxCox":     func() Scaler { return &BoxCoxScaler{} },
+	"yeoJohnson": func() Scaler { return &YeoJohnsonScaler{} },
+	"chain":      func() Scaler { return &ChainScaler{} },
+}
+
+func marshalScaler(s Scaler) (*savedScaler, error) {
+	var name string
+	switch s.(type) {
+	case *StandardScaler:
+		name = "standard"
+	case *MinMaxScaler:
+		name = "minMax"
+	case *RobustScaler:
+		name = "robust"
+	case *MaxAbsScaler:
+		name = "maxAbs"
+	case *Log1pScaler:
+		name = "log1p"
+	case *BoxCoxScaler:
+		name = "boxCox"
+	case *YeoJohnsonScaler:
+		name = "yeoJohnson"
+	case *ChainScaler:
+		name = "chain"
+	default:
+		return nil, fmt.Errorf("can't save scaler of type %T", s)
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return &savedScaler{Type: name, Scaler: data}, nil
+}
+
+func unmarshalScaler(saved *savedScaler) (Scaler, error) {
+	newScaler, ok := scalerTypes[saved.Type]
+	if !ok {
+		return nil, fmt.Errorf("unknown scaler type %q", saved.Type)
+	}
+	result := newScaler()
+	if err := json.Unmarshal(saved.Scaler, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,69 @@
+package ml
+
+import (
+	"math"
+	"testing"
+)
+
+func TestStandardScaler(t *testing.T) {
+	X := [][]float64{
+		{1, 2, 10},
+		{1, 4, 10},
+		{1, 6, 10},
+		{1, 8, 10},
+	}
+	tests := []struct {
+		name   string
+		scaler *StandardScaler
+		want   [][]float64
+	}{
+		//the constant columns can't be scaled so they are left as they are
+		{"constant columns", &StandardScaler{}, [][]float64{
+			{1, -3 / math.Sqrt(5), 10},
+			{1, -1 / math.Sqrt(5), 10},
+			{1, 1 / math.Sqrt(5), 10},
+			{1, 3 / math.Sqrt(5), 10},
+		}},
+		{"skip", &StandardScaler{Skip: []int{1}}, X},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := FitTransform(test.scaler, X)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertClose(t, "Transform", got, test.want, 1e-12)
+			assertClose(t, "InverseTransform", test.scaler.InverseTransform(got), X, 1e-12)
+		})
+	}
+}
+
+func TestStandardScalerFit(t *testing.T) {
+	s := &StandardScaler{}
+	if err := s.Fit([][]float64{{1, 5}, {3, 5}}); err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "Means", [][]float64{s.Means}, [][]float64{{2, 5}}, 0)
+	assertClose(t, "Stds", [][]float64{s.Stds}, [][]float64{{1, 0}}, 0)
+
+	//new data is scaled with what was fit, not with its own mean
+	assertClose(t, "Transform", s.Transform([][]float64{{4, 6}}), [][]float64{{2, 6}}, 0)
+
+	assertErrorContains(t, s.Fit(nil), "no data")
+}
+
+func TestScalerSkipOutOfRange(t *testing.T) {
+	X := [][]float64{{1, 2}, {3, 5}}
+	scalers := []Scaler{
+		&StandardScaler{Skip: []int{2}},
+		&MinMaxScaler{Skip: []int{2}},
+		&RobustScaler{Skip: []int{-1}},
+		&MaxAbsScaler{Skip: []int{2}},
+		&Log1pScaler{Skip: []int{5}},
+		&BoxCoxScaler{Skip: []int{2}},
+		&YeoJohnsonScaler{Skip: []int{2}},
+	}
+	for _, scaler := range scalers {
+		assertErrorContains(t, scaler.Fit(X), "out of range of 2 columns")
+	}
+}
@@ -0,0 +1,477 @@
+package ml
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// MinMaxScaler rescales every column to the range Min through Max, which
+// defaults to 0 through 1.  Columns that hold a single value are left
+// unchanged.
+type MinMaxScaler struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+	//Skip are the columns that are left unchanged.
+	Skip []int `json:"skip,omitempty"`
+
+	DataMin []float64 `json:"dataMin"`
+	DataMax []float64 `json:"dataMax"`
+}
+
+// Fit finds the smallest and largest value of every column of X.
+func (s *MinMaxScaler) Fit(X [][]float64) error {
+	if len(X) == 0 {
+		return fmt.Errorf("min-max scaler: no data to fit")
+	}
+	if err := checkSkip(s.Skip, len(X[0])); err != nil {
+		return fmt.Errorf("min-max scaler: %v", err)
+	}
+	if s.Min == 0 && s.Max == 0 {
+		s.Max = 1
+	}
+	if s.Min >= s.Max {
+		return fmt.Errorf("min-max scaler: invalid range %v to %v", s.Min, s.Max)
+	}
+	s.DataMin = append([]float64(nil), X[0]...)
+	s.DataMax = append([]float64(nil), X[0]...)
+	for r := range X {
+		for c, v := range X[r] {
+			s.DataMin[c] = math.Min(s.DataMin[c], v)
+			s.DataMax[c] = math.Max(s.DataMax[c], v)
+		}
+	}
+	return nil
+}
+
+// Transform returns a rescaled copy of X.
+func (s *MinMaxScaler) Transform(X [][]float64) [][]float64 {
+	return transformColumns(X, s.skipped(), func(c int, v float64) float64 {
+		return s.Min + (v-s.DataMin[c])/(s.DataMax[c]-s.DataMin[c])*(s.Max-s.Min)
+	})
+}
+
+// InverseTransform converts rescaled data back to the original units.
+func (s *MinMaxScaler) InverseTransform(X [][]float64) [][]float64 {
+	return transformColumns(X, s.skipped(), func(c int, v float64) float64 {
+		return s.DataMin[c] + (v-s.Min)/(s.Max-s.Min)*(s.DataMax[c]-s.DataMin[c])
+	})
+}
+
+func (s *MinMaxScaler) skipped() []bool {
+	ranges := make([]float64, len(s.DataMin))
+	for c := range ranges {
+		ranges[c] = s.DataMax[c] - s.DataMin[c]
+	}
+	return skipped(len(ranges), s.Skip, ranges)
+}
+
+// RobustScaler centers every column on its median and divides it by its
+// interquartile range, so a few outliers have little effect on the scaling.
+// Columns with an interquartile range of zero are left unchanged.
+type RobustScaler struct {
+	//Skip are the columns that are left unchanged.
+	Skip []int `json:"skip,omitempty"`
+
+	Medians []float64 `json:"medians"`
+	IQRs    []float64 `json:"iqrs"`
+}
+
+// Fit finds the median and interquartile range of every column of X.
+func (s *RobustScaler) Fit(X [][]float64) error {
+	if len(X) == 0 {
+		return fmt.Errorf("robust scaler: no data to fit")
+	}
+	if err := checkSkip(s.Skip, len(X[0])); err != nil {
+		return fmt.Errorf("robust scaler: %v", err)
+	}
+	s.Medians = make([]float64, len(X[0]))
+	s.IQRs = make([]float64, len(X[0]))
+	for c := range s.Medians {
+		sorted := Column(X, c)
+		sort.Float64s(sorted)
+		s.Medians[c] = quantile(sorted, 0.5)
+		s.IQRs[c] = quantile(sorted, 0.75) - quantile(sorted, 0.25)
+	}
+	return nil
+}
+
+// Transform returns a rescaled copy of X.
+func (s *RobustScaler) Transform(X [][]float64) [][]float64 {
+	return transformColumns(X, skipped(len(s.IQRs), s.Skip, s.IQRs), func(c int, v float64) float64 {
+		return (v - s.Medians[c]) / s.IQRs[c]
+	})
+}
+
+// InverseTransform converts rescaled data back to the original units.
+func (s *RobustScaler) InverseTransform(X [][]float64) [][]float64 {
+	return transformColumns(X, skipped(len(s.IQRs), s.Skip, s.IQRs), func(c int, v float64) float64 {
+		return v*s.IQRs[c] + s.Medians[c]
+	})
+}
+
+// MaxAbsScaler divides every column by its largest absolute value, which maps
+// it into -1 through 1 without moving the zeros.  Columns of all zeros are
+// left unchanged.
+type MaxAbsScaler struct {
+	//Skip are the columns that are left unchanged.
+	Skip []int `json:"skip,omitempty"`
+
+	MaxAbs []float64 `json:"maxAbs"`
+}
+
+// Fit finds the largest absolute value of every column of X.
+func (s *MaxAbsScaler) Fit(X [][]float64) error {
+	if len(X) == 0 {
+		return fmt.Errorf("max-abs scaler: no data to fit")
+	}
+	if err := checkSkip(s.Skip, len(X[0])); err != nil {
+		return fmt.Errorf("max-abs scaler: %v", err)
+	}
+	s.MaxAbs = make([]float64, len(X[0]))
+	for r := range X {
+		for c, v := range X[r] {
+			s.MaxAbs[c] = math.Max(s.MaxAbs[c], math.Abs(v))
+		}
+	}
+	return nil
+}
+
+// Transform returns a rescaled copy of X.
+func (s *MaxAbsScaler) Transform(X [][]float64) [][]float64 {
+	return transformColumns(X, skipped(len(s.MaxAbs), s.Skip, s.MaxAbs), func(c int, v float64) float64 {
+		return v / s.MaxAbs[c]
+	})
+}
+
+// InverseTransform converts rescaled data back to the original units.
+func (s *MaxAbsScaler) InverseTransform(X [][]float64) [][]float64 {
+	return transformColumns(X, skipped(len(s.MaxAbs), s.Skip, s.MaxAbs), func(c int, v float64) float64 {
+		return v * s.MaxAbs[c]
+	})
+}
+
+// Log1pScaler replaces every value x with log(1 + x), which pulls in the long
+// tail of skewed columns.  Columns that hold a single value, such as the bias
+// column, are left unchanged.
+type Log1pScaler struct {
+	//Skip are the columns that are left unchanged.
+	Skip []int `json:"skip,omitempty"`
+
+	//Constant are the columns that held a single value during Fit.
+	Constant []int `json:"constant,omitempty"`
+}
+
+// Fit checks that every value of X can be transformed.
+func (s *Log1pScaler) Fit(X [][]float64) error {
+	if len(X) == 0 {
+		return fmt.Errorf("log1p scaler: no data to fit")
+	}
+	if err := checkSkip(s.Skip, len(X[0])); err != nil {
+		return fmt.Errorf("log1p scaler: %v", err)
+	}
+	s.Constant = constantColumns(X)
+	skip := s.skipped(len(X[0]))
+	for r := range X {
+		for c, v := range X[r] {
+			if !skip[c] && v <= -1 {
+				return fmt.Errorf("log1p scaler: column %d has value %v, values must be more than -1", c, v)
+			}
+		}
+	}
+	return nil
+}
+
+// Transform returns a transformed copy of X.
+func (s *Log1pScaler) Transform(X [][]float64) [][]float64 {
+	return transformColumns(X, s.skipped(rowLength(X)), func(c int, v float64) float64 {
+		return math.Log1p(v)
+	})
+}
+
+// InverseTransform converts transformed data back to the original units.
+func (s *Log1pScaler) InverseTransform(X [][]float64) [][]float64 {
+	return transformColumns(X, s.skipped(rowLength(X)), func(c int, v float64) float64 {
+		return math.Expm1(v)
+	})
+}
+
+func (s *Log1pScaler) skipped(columns int) []bool {
+	return skipped(columns, append(append([]int(nil), s.Skip...), s.Constant...))
+}
+
+// BoxCoxScaler applies the Box-Cox power transform to every column, with the
+// power (lambda) chosen by maximum likelihood to make the column as close to
+// normally distributed as possible.  The values must be positive.  Columns
+// that hold a single value are left unchanged.
+type BoxCoxScaler struct {
+	//Skip are the columns that are left unchanged.
+	Skip []int `json:"skip,omitempty"`
+
+	Lambdas []float64 `json:"lambdas"`
+	//Constant are the columns that held a single value during Fit.
+	Constant []int `json:"constant,omitempty"`
+}
+
+// Fit finds the lambda of every column of X.
+func (s *BoxCoxScaler) Fit(X [][]float64) error {
+	if len(X) == 0 {
+		return fmt.Errorf("box-cox scaler: no data to fit")
+	}
+	if err := checkSkip(s.Skip, len(X[0])); err != nil {
+		return fmt.Errorf("box-cox scaler: %v", err)
+	}
+	s.Constant = constantColumns(X)
+	s.Lambdas = make([]float64, len(X[0]))
+	skip := s.skipped()
+	for c := range s.Lambdas {
+		if skip[c] {
+			s.Lambdas[c] = 1
+			continue
+		}
+		values := Column(X, c)
+		logSum := 0.0
+		for _, v := range values {
+			if v <= 0 {
+				return fmt.Errorf("box-cox scaler: column %d has value %v, values must be positive", c, v)
+			}
+			logSum += math.Log(v)
+		}
+		transformed := make([]float64, len(values))
+		s.Lambdas[c] = maximize(func(lambda float64) float64 {
+			for i, v := range values {
+				transformed[i] = boxCox(v, lambda)
+			}
+			return -float64(len(values))/2*math.Log(variance(transformed)) + (lambda-1)*logSum
+		})
+	}
+	return nil
+}
+
+// Transform returns a transformed copy of X.
+func (s *BoxCoxScaler) Transform(X [][]float64) [][]float64 {
+	return transformColumns(X, s.skipped(), func(c int, v float64) float64 {
+		return boxCox(v, s.Lambdas[c])
+	})
+}
+
+// InverseTransform converts transformed data back to the original units.
+func (s *BoxCoxScaler) InverseTransform(X [][]float64) [][]float64 {
+	return transformColumns(X, s.skipped(), func(c int, v float64) float64 {
+		if s.Lambdas[c] == 0 {
+			return math.Exp(v)
+		}
+		return math.Pow(s.Lambdas[c]*v+1, 1/s.Lambdas[c])
+	})
+}
+
+func (s *BoxCoxScaler) skipped() []bool {
+	return skipped(len(s.Lambdas), append(append([]int(nil), s.Skip...), s.Constant...))
+}
+
+func boxCox(v float64, lambda float64) float64 {
+	if lambda == 0 {
+		return math.Log(v)
+	}
+	return (math.Pow(v, lambda) - 1) / lambda
+}
+
+// YeoJohnsonScaler applies the Yeo-Johnson power transform to every column,
+// which works like Box-Cox but also accepts zero and negative values.  Columns
+// that hold a single value are left unchanged.
+type YeoJohnsonScaler struct {
+	//Skip are the columns that are left unchanged.
+	Skip []int `json:"skip,omitempty"`
+
+	Lambdas []float64 `json:"lambdas"`
+	//Constant are the columns that held a single value during Fit.
+	Constant []int `json:"constant,omitempty"`
+}
+
+// Fit finds the lambda of every column of X.
+func (s *YeoJohnsonScaler) Fit(X [][]float64) error {
+	if len(X) == 0 {
+		return fmt.Errorf("yeo-johnson scaler: no data to fit")
+	}
+	if err := checkSkip(s.Skip, len(X[0])); err != nil {
+		return fmt.Errorf("yeo-johnson scaler: %v", err)
+	}
+	s.Constant = constantColumns(X)
+	s.Lambdas = make([]float64, len(X[0]))
+	skip := s.skipped()
+	for c := range s.Lambdas {
+		if skip[c] {
+			s.Lambdas[c] = 1
+			continue
+		}
+		values := Column(X, c)
+		logSum := 0.0
+		for _, v := range values {
+			if v >= 0 {
+				logSum += math.Log1p(v)
+			} else {
+				logSum -= math.Log1p(-v)
+			}
+		}
+		transformed := make([]float64, len(values))
+		s.Lambdas[c] = maximize(func(lambda float64) float64 {
+			for i, v := range values {
+				transformed[i] = yeoJohnson(v, lambda)
+			}
+			return -float64(len(values))/2*math.Log(variance(transformed)) + (lambda-1)*logSum
+		})
+	}
+	return nil
+}
+
+// Transform returns a transformed copy of X.
+func (s *YeoJohnsonScaler) Transform(X [][]float64) [][]float64 {
+	return transformColumns(X, s.skipped(), func(c int, v float64) float64 {
+		return yeoJohnson(v, s.Lambdas[c])
+	})
+}
+
+// InverseTransform converts transformed data back to the original units.
+func (s *YeoJohnsonScaler) InverseTransform(X [][]float64) [][]float64 {
+	return transformColumns(X, s.skipped(), func(c int, v float64) float64 {
+		lambda := s.Lambdas[c]
+		switch {
+		case v >= 0 && lambda == 0:
+			return math.Expm1(v)
+		case v >= 0:
+			return math.Pow(lambda*v+1, 1/lambda) - 1
+		case lambda == 2:
+			return -math.Expm1(-v)
+		default:
+			return 1 - math.Pow(-(2-lambda)*v+1, 1/(2-lambda))
+		}
+	})
+}
+
+func (s *YeoJohnsonScaler) skipped() []bool {
+	return skipped(len(s.Lambdas), append(append([]int(nil), s.Skip...), s.Constant...))
+}
+
+func yeoJohnson(v float64, lambda float64) float64 {
+	switch {
+	case v >= 0 && lambda == 0:
+		return math.Log1p(v)
+	case v >= 0:
+		return (math.Pow(v+1, lambda) - 1) / lambda
+	case lambda == 2:
+		return -math.Log1p(-v)
+	default:
+		return -(math.Pow(-v+1, 2-lambda) - 1) / (2 - lambda)
+	}
+}
+
+// ChainScaler applies several scalers one after the other, for example a
+// power transform followed by standardization.
+type ChainScaler struct {
+	Scalers []Scaler
+}
+
+// Fit fits every scaler to the output of the one before it.
+func (s *ChainScaler) Fit(X [][]float64) error {
+	for _, scaler := range s.Scalers {
+		var err error
+		if X, err = FitTransform(scaler, X); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Transform runs X through every scaler.
+func (s *ChainScaler) Transform(X [][]float64) [][]float64 {
+	for _, scaler := range s.Scalers {
+		X = scaler.Transform(X)
+	}
+	return X
+}
+
+// InverseTransform runs X back through every scaler in reverse order.
+func (s *ChainScaler) InverseTransform(X [][]float64) [][]float64 {
+	for i := len(s.Scalers) - 1; i >= 0; i-- {
+		X = s.Scalers[i].InverseTransform(X)
+	}
+	return X
+}
+
+// MarshalJSON saves every scaler with the name of its type.
+func (s ChainScaler) MarshalJSON() ([]byte, error) {
+	var saved []*savedScaler
+	for _, scaler := range s.Scalers {
+		v, err := marshalScaler(scaler)
+		if err != nil {
+			return nil, err
+		}
+		saved = append(saved, v)
+	}
+	return json.Marshal(saved)
+}
+
+// UnmarshalJSON reads scalers saved by MarshalJSON.
+func (s *ChainScaler) UnmarshalJSON(data []byte) error {
+	var saved []*savedScaler
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return err
+	}
+	s.Scalers = nil
+	for _, v := range saved {
+		scaler, err := unmarshalScaler(v)
+		if err != nil {
+			return err
+		}
+		s.Scalers = append(s.Scalers, scaler)
+	}
+	return nil
+}
+
+// constantColumns returns the columns of X that hold a single value.
+func constantColumns(X [][]float64) []int {
+	var result []int
+	for c := range X[0] {
+		constant := true
+		for r := range X {
+			if X[r][c] != X[0][c] {
+				constant = false
+				break
+			}
+		}
+		if constant {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+func rowLength(X [][]float64) int {
+	if len(X) == 0 {
+		return 0
+	}
+	return len(X[0])
+}
+
+// maximize finds the lambda in -5 through 5 where f is largest with a golden
+// section search.
+func maximize(f func(lambda float64) float64) float64 {
+	ratio := (math.Sqrt(5) - 1) / 2
+	low, high := -5.0, 5.0
+	a := high - ratio*(high-low)
+	b := low + ratio*(high-low)
+	fa, fb := f(a), f(b)
+	for high-low > 1e-6 {
+		if fa > fb {
+			high, b, fb = b, a, fa
+			a = high - ratio*(high-low)
+			fa = f(a)
+		} else {
+			low, a, fa = a, b, fb
+			b = low + ratio*(high-low)
+			fb = f(b)
+		}
+	}
+	return (low + high) / 2
+}
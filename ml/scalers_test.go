@@ -0,0 +1,116 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestScalerInverseTransform(t *testing.T) {
+	//a skewed column, a column with a negative value and a constant column
+	X := [][]float64{
+		{1, 0.5, 3},
+		{2, -0.25, 3},
+		{4, 2, 3},
+		{8, 1, 3},
+		{40, 6, 3},
+	}
+	positive := SelectColumns(X, []int{0, 2})
+	tests := []struct {
+		scaler Scaler
+		X      [][]float64
+		//keepsConstant is set for the scalers that leave a constant column as it is
+		keepsConstant bool
+	}{
+		{&MinMaxScaler{}, X, true},
+		{&MinMaxScaler{Min: -1, Max: 1}, X, true},
+		{&RobustScaler{}, X, true},
+		{&MaxAbsScaler{}, X, false},
+		{&Log1pScaler{}, X, true},
+		{&BoxCoxScaler{}, positive, true},
+		{&YeoJohnsonScaler{}, X, true},
+		{&ChainScaler{Scalers: []Scaler{&YeoJohnsonScaler{}, &StandardScaler{}}}, X, true},
+		{&ChainScaler{Scalers: []Scaler{&BoxCoxScaler{}, &MinMaxScaler{}}}, positive, true},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%T", test.scaler), func(t *testing.T) {
+			transformed, err := FitTransform(test.scaler, test.X)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertClose(t, "InverseTransform", test.scaler.InverseTransform(transformed), test.X, 1e-9)
+
+			if !test.keepsConstant {
+				return
+			}
+			last := len(test.X[0]) - 1
+			for r := range transformed {
+				if transformed[r][last] != 3 {
+					t.Fatalf("constant column = %v, want 3", transformed[r][last])
+				}
+			}
+		})
+	}
+}
+
+func TestMinMaxScaler(t *testing.T) {
+	s := &MinMaxScaler{Min: -1, Max: 1}
+	got, err := FitTransform(s, [][]float64{{0, 5}, {5, 5}, {10, 5}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "Transform", got, [][]float64{{-1, 5}, {0, 5}, {1, 5}}, 1e-12)
+	assertErrorContains(t, (&MinMaxScaler{Min: 1, Max: 1}).Fit([][]float64{{1}}), "invalid range 1 to 1")
+}
+
+// The lambdas were found by a fine grid search over the Box-Cox and
+// Yeo-Johnson log likelihood, separately from the search used by Fit.
+func TestPowerTransformLambdas(t *testing.T) {
+	tests := []struct {
+		name   string
+		scaler Scaler
+		values []float64
+		lambda float64
+	}{
+		{"box-cox 1 to 10", &BoxCoxScaler{}, []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, 0.7219641},
+		//the logs are evenly spaced, so the log (lambda 0) makes them symmetric
+		{"box-cox powers of 2", &BoxCoxScaler{}, []float64{0.5, 1, 2, 4, 8, 16, 32}, 0},
+		{"box-cox skewed", &BoxCoxScaler{}, []float64{1, 1.5, 2, 3, 10, 40}, -0.4421338},
+		//yeo-johnson of values 0 or more is box-cox of the values plus 1
+		{"yeo-johnson 0 to 9", &YeoJohnsonScaler{}, []float64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, 0.7219641},
+		{"yeo-johnson skewed", &YeoJohnsonScaler{}, []float64{-3, -1, 0, 1, 2, 4, 9, 20}, 0.5021818},
+		{"yeo-johnson negative", &YeoJohnsonScaler{}, []float64{-10, -4, -1, 0, 1, 2}, 1.6038749},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			X := make([][]float64, len(test.values))
+			for r, v := range test.values {
+				X[r] = []float64{v}
+			}
+			if err := test.scaler.Fit(X); err != nil {
+				t.Fatal(err)
+			}
+			var got float64
+			switch s := test.scaler.(type) {
+			case *BoxCoxScaler:
+				got = s.Lambdas[0]
+			case *YeoJohnsonScaler:
+				got = s.Lambdas[0]
+			}
+			if math.Abs(got-test.lambda) > 1e-5 {
+				t.Fatalf("lambda = %v, want %v", got, test.lambda)
+			}
+		})
+	}
+}
+
+func TestPowerTransformErrors(t *testing.T) {
+	assertErrorContains(t, (&BoxCoxScaler{}).Fit([][]float64{{1}, {0}, {2}}), "column 0 has value 0, values must be positive")
+	assertErrorContains(t, (&BoxCoxScaler{}).Fit([][]float64{{1, 2}, {3, -1}}), "column 1 has value -1, values must be positive")
+	assertErrorContains(t, (&Log1pScaler{}).Fit([][]float64{{1}, {-1}}), "column 0 has value -1, values must be more than -1")
+
+	//a skipped column isn't checked
+	if err := (&BoxCoxScaler{Skip: []int{1}}).Fit([][]float64{{1, 0}, {2, -1}}); err != nil {
+		t.Fatal(err)
+	}
+}
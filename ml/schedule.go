@@ -0,0 +1,191 @@
+package ml
+
+import (
+	"math"
+)
+
+// Schedule changes the learning rate from one epoch to the next.
+type Schedule interface {
+	//Rate returns the learning rate for an epoch, counting from 0, given the
+	//starting rate.
+	Rate(epoch int, rate float64) float64
+}
+
+// Observer is a Schedule that adapts to the error measured after each epoch.
+// SGD passes it the mean validation RMSE of the outputs, or the training RMSE
+// when there is no validation data.
+type Observer interface {
+	Schedule
+	Observe(epoch int, rmse float64)
+}
+
+// StepDecay multiplies the rate by Factor every Step epochs.
+type StepDecay struct {
+	Step int
+	//Factor defaults to 0.5.
+	Factor float64
+}
+
+// Rate returns the decayed rate.
+func (s StepDecay) Rate(epoch int, rate float64) float64 {
+	if s.Step <= 0 {
+		return rate
+	}
+	return rate * math.Pow(defaultValue(s.Factor, 0.5), float64(epoch/s.Step))
+}
+
+// ExponentialDecay multiplies the rate by Decay every epoch.
+type ExponentialDecay struct {
+	//Decay defaults to 0.95.
+	Decay float64
+}
+
+// Rate returns the decayed rate.
+func (s ExponentialDecay) Rate(epoch int, rate float64) float64 {
+	return rate * math.Pow(defaultValue(s.Decay, 0.95), float64(epoch))
+}
+
+// InverseTimeDecay divides the rate by 1 + Decay * epoch.
+type InverseTimeDecay struct {
+	//Decay defaults to 1.
+	Decay float64
+}
+
+// Rate returns the decayed rate.
+func (s InverseTimeDecay) Rate(epoch int, rate float64) float64 {
+	return rate / (1 + defaultValue(s.Decay, 1)*float64(epoch))
+}
+
+// CosineAnnealing lowers the rate from its starting value to MinRate along
+// half a cosine wave over Period epochs, then jumps back up and starts again
+// (a warm restart).
+type CosineAnnealing struct {
+	Period  int
+	MinRate float64
+	//Multiplier makes every period this many times longer than the last, it
+	//defaults to 1.
+	Multiplier int
+}
+
+// Rate returns the rate at this point of the current period.
+func (s CosineAnnealing) Rate(epoch int, rate float64) float64 {
+	if s.Period <= 0 {
+		return rate
+	}
+	multiplier := s.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	//find the period the epoch is in
+	period := s.Period
+	for epoch >= period {
+		epoch -= period
+		period *= multiplier
+	}
+	return s.MinRate + (rate-s.MinRate)*(1+math.Cos(math.Pi*float64(epoch)/float64(period)))/2
+}
+
+// LinearWarmup raises the rate in a straight line from rate / Epochs up to
+// the full rate over the first Epochs epochs, which keeps the first updates
+// from large early gradients small.  Schedule, when set, takes over after the
+// warmup, counting its epochs from the end of the warmup.
+type LinearWarmup struct {
+	Epochs   int
+	Schedule Schedule
+}
+
+// Rate returns the warmup rate, or the rate of the following schedule.
+func (s LinearWarmup) Rate(epoch int, rate float64) float64 {
+	if epoch < s.Epochs {
+		return rate * float64(epoch+1) / float64(s.Epochs)
+	}
+	if s.Schedule == nil {
+		return rate
+	}
+	return s.Schedule.Rate(epoch-s.Epochs, rate)
+}
+
+// Observe passes the error after the warmup on when the following schedule
+// is an Observer.
+func (s LinearWarmup) Observe(epoch int, rmse float64) {
+	if epoch < s.Epochs {
+		return
+	}
+	if o, ok := s.Schedule.(Observer); ok {
+		o.Observe(epoch-s.Epochs, rmse)
+	}
+}
+
+// ReduceOnPlateau multiplies the rate by Factor whenever the error has not
+// improved for Patience epochs.  After each reduction it can wait Cooldown
+// epochs for the lower rate to take effect before counting again.
+type ReduceOnPlateau struct {
+	//Factor defaults to 0.1.
+	Factor float64
+	//Patience is the number of epochs without improvement to wait.
+	Patience int
+	//MinDelta is how much the error has to drop by to count as an
+	//improvement.
+	MinDelta float64
+	//MinRate is the lowest the rate is reduced to.
+	MinRate float64
+	//Cooldown is the number of epochs after a reduction that are not counted
+	//as epochs without improvement.
+	Cooldown int
+
+	//Scale is what the starting rate is multiplied by so far, Best is the
+	//lowest error seen, Wait the number of epochs since it was seen and
+	//Cooling the number of epochs of cooldown left.
+	Scale   float64
+	Best    float64
+	Wait    int
+	Cooling int
+}
+
+// Rate returns the reduced rate.
+func (s *ReduceOnPlateau) Rate(epoch int, rate float64) float64 {
+	if s.Scale == 0 {
+		s.Scale = 1
+	}
+	return math.Max(rate*s.Scale, s.MinRate)
+}
+
+// Observe records the error after an epoch and reduces the rate once it has
+// stopped improving.
+func (s *ReduceOnPlateau) Observe(epoch int, rmse float64) {
+	if s.Scale == 0 {
+		s.Scale = 1
+	}
+	cooling := s.Cooling > 0
+	if cooling {
+		s.Cooling--
+	}
+	if epoch == 0 || rmse < s.Best-s.MinDelta {
+		s.Best = rmse
+		s.Wait = 0
+		return
+	}
+	if cooling {
+		return
+	}
+	s.Wait++
+	if s.Wait >= s.Patience {
+		s.Scale *= defaultValue(s.Factor, 0.1)
+		s.Wait = 0
+		s.Cooling = s.Cooldown
+	}
+}
+
+// plateauOf returns the ReduceOnPlateau of a schedule, on its own or after a
+// LinearWarmup, or nil when there is none.
+func plateauOf(schedule Schedule) *ReduceOnPlateau {
+	switch s := schedule.(type) {
+	case *ReduceOnPlateau:
+		return s
+	case LinearWarmup:
+		return plateauOf(s.Schedule)
+	case *LinearWarmup:
+		return plateauOf(s.Schedule)
+	}
+	return nil
+}
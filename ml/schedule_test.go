@@ -0,0 +1,70 @@
+package ml
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSchedules(t *testing.T) {
+	//cos(pi / 4) of the way down a period of 4
+	quarter := 0.2 + 0.4*(1+math.Sqrt(0.5))
+	threeQuarters := 0.2 + 0.4*(1-math.Sqrt(0.5))
+	tests := []struct {
+		name     string
+		schedule Schedule
+		want     []float64
+	}{
+		{"step", StepDecay{Step: 2, Factor: 0.5}, []float64{1, 1, 0.5, 0.5, 0.25}},
+		{"step not set", StepDecay{}, []float64{1, 1, 1}},
+		{"exponential", ExponentialDecay{Decay: 0.5}, []float64{1, 0.5, 0.25, 0.125}},
+		{"inverse time", InverseTimeDecay{Decay: 0.5}, []float64{1, 1 / 1.5, 0.5, 1 / 2.5}},
+		{"cosine", CosineAnnealing{Period: 3, MinRate: 0.2}, []float64{1, 0.8, 0.4, 1, 0.8, 0.4, 1}},
+		//periods of 2, 4 and 8 epochs restart at epochs 2 and 6
+		{"cosine with longer periods", CosineAnnealing{Period: 2, MinRate: 0.2, Multiplier: 2}, []float64{1, 0.6, 1, quarter, 0.6, threeQuarters, 1, 0.2 + 0.4*(1+math.Cos(math.Pi/8))}},
+		{"warmup", LinearWarmup{Epochs: 4}, []float64{0.25, 0.5, 0.75, 1, 1}},
+		{"warmup then exponential", LinearWarmup{Epochs: 2, Schedule: ExponentialDecay{Decay: 0.5}}, []float64{0.5, 1, 1, 0.5, 0.25}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for epoch, want := range test.want {
+				if got := test.schedule.Rate(epoch, 1); math.Abs(got-want) > 1e-12 {
+					t.Fatalf("Rate(%d) = %v, want %v", epoch, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestReduceOnPlateau(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule Observer
+		errors   []float64
+		want     []float64
+	}{
+		{"patience", &ReduceOnPlateau{Factor: 0.5, Patience: 2}, []float64{1, 0.9, 0.95, 0.95, 0.95, 0.95, 0.8, 0.85}, []float64{1, 1, 1, 0.5, 0.5, 0.25, 0.25, 0.25}},
+		{"default factor", &ReduceOnPlateau{Patience: 1}, []float64{1, 1}, []float64{1, 0.1}},
+		//0.85 is not enough of an improvement on 1, 0.75 is
+		{"min delta", &ReduceOnPlateau{Factor: 0.5, Patience: 2, MinDelta: 0.2}, []float64{1, 0.85, 0.75, 0.6, 0.6}, []float64{1, 1, 1, 1, 0.5}},
+		{"min rate", &ReduceOnPlateau{Factor: 0.5, Patience: 1, MinRate: 0.3}, []float64{1, 1, 1, 1}, []float64{1, 0.5, 0.3, 0.3}},
+		{"no cooldown", &ReduceOnPlateau{Factor: 0.5, Patience: 1}, []float64{1, 1, 1, 1, 1, 1}, []float64{1, 0.5, 0.25, 0.125, 0.0625, 0.03125}},
+		//the 2 epochs after each cut are not counted
+		{"cooldown", &ReduceOnPlateau{Factor: 0.5, Patience: 1, Cooldown: 2}, []float64{1, 1, 1, 1, 1, 1, 1}, []float64{1, 0.5, 0.5, 0.5, 0.25, 0.25, 0.25}},
+		//an improvement during the cooldown still sets the best error
+		{"improving in cooldown", &ReduceOnPlateau{Factor: 0.5, Patience: 1, Cooldown: 2}, []float64{1, 1, 0.5, 0.6, 0.6}, []float64{1, 0.5, 0.5, 0.5, 0.25}},
+		//the warmup epochs are not observed and the plateau counts from the end
+		//of the warmup
+		{"after warmup", LinearWarmup{Epochs: 2, Schedule: &ReduceOnPlateau{Factor: 0.5, Patience: 1}}, []float64{3, 2, 1, 1, 1}, []float64{1, 1, 1, 0.5, 0.25}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for epoch, rmse := range test.errors {
+				test.schedule.Observe(epoch, rmse)
+				//the rate for the next epoch
+				if got := test.schedule.Rate(epoch+1, 1); math.Abs(got-test.want[epoch]) > 1e-12 {
+					t.Fatalf("rate after observing %v at epoch %d = %v, want %v", rmse, epoch, got, test.want[epoch])
+				}
+			}
+		})
+	}
+}
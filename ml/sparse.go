@@ -0,0 +1,302 @@
+package ml
+
+import (
+	"fmt"
+	"sort"
+)
+
+// CSR is a sparse matrix stored by rows (compressed sparse row).  Only the
+// non-zero values are kept, so a wide matrix such as one hot encoded
+// categories takes memory in proportion to the values that are set rather
+// than rows x columns.
+type CSR struct {
+	rows, cols int
+	//indptr[i] through indptr[i+1]-1 are the positions in indices and data
+	//of the values of row i.
+	indptr  []int
+	indices []int
+	data    []float64
+}
+
+// CSC is a sparse matrix stored by columns (compressed sparse column).  It is
+// the better layout for walking down a column, a CSR is better for walking
+// along a row.
+type CSC struct {
+	rows, cols int
+	//indptr[j] through indptr[j+1]-1 are the positions in indices and data
+	//of the values of column j.
+	indptr  []int
+	indices []int
+	data    []float64
+}
+
+// NewCSR returns a rows x cols matrix using indptr, indices and data as its
+// storage.  The values of row i are data[indptr[i]:indptr[i+1]], in the
+// columns held in the same positions of indices, which must be increasing
+// within a row.
+func NewCSR(rows int, cols int, indptr []int, indices []int, data []float64) *CSR {
+	checkCompressed(rows, cols, indptr, indices, data)
+	return &CSR{rows: rows, cols: cols, indptr: indptr, indices: indices, data: data}
+}
+
+// NewCSC returns a rows x cols matrix using indptr, indices and data as its
+// storage.  The values of column j are data[indptr[j]:indptr[j+1]], in the
+// rows held in the same positions of indices, which must be increasing within
+// a column.
+func NewCSC(rows int, cols int, indptr []int, indices []int, data []float64) *CSC {
+	checkCompressed(cols, rows, indptr, indices, data)
+	return &CSC{rows: rows, cols: cols, indptr: indptr, indices: indices, data: data}
+}
+
+// checkCompressed panics unless indptr, indices and data describe n
+// compressed rows (or columns) of length m.
+func checkCompressed(n int, m int, indptr []int, indices []int, data []float64) {
+	if len(indptr) != n+1 || indptr[0] != 0 || indptr[n] != len(indices) || len(indices) != len(data) {
+		panic(fmt.Sprintf("ml: %d pointers, %d indices and %d values don't describe %d rows", len(indptr), len(indices), len(data), n))
+	}
+	for i := 0; i < n; i++ {
+		if indptr[i] > indptr[i+1] {
+			panic(fmt.Sprintf("ml: pointers decrease at %d", i))
+		}
+		for p := indptr[i]; p < indptr[i+1]; p++ {
+			if indices[p] < 0 || indices[p] >= m || (p > indptr[i] && indices[p] <= indices[p-1]) {
+				panic(fmt.Sprintf("ml: index %d out of order or out of range %d", indices[p], m))
+			}
+		}
+	}
+}
+
+// CSROf copies the non-zero values of a [][]float64 matrix into a new CSR.
+func CSROf(m [][]float64) *CSR {
+	result := &CSR{rows: len(m), indptr: make([]int, 1, len(m)+1)}
+	if len(m) > 0 {
+		result.cols = len(m[0])
+	}
+	for _, row := range m {
+		for c, v := range row {
+			if v != 0 {
+				result.indices = append(result.indices, c)
+				result.data = append(result.data, v)
+			}
+		}
+		result.indptr = append(result.indptr, len(result.indices))
+	}
+	return result
+}
+
+// Dims returns the number of rows and columns.
+func (s *CSR) Dims() (int, int) {
+	return s.rows, s.cols
+}
+
+// NNZ returns the number of values that are stored.
+func (s *CSR) NNZ() int {
+	return len(s.data)
+}
+
+// At returns the value at row i, column j.
+func (s *CSR) At(i int, j int) float64 {
+	return compressedAt(s.indptr, s.indices, s.data, i, j)
+}
+
+// RowNonZeros returns the columns and values of the non-zero entries of row
+// i, sharing memory with the matrix.
+func (s *CSR) RowNonZeros(i int) ([]int, []float64) {
+	start, end := s.indptr[i], s.indptr[i+1]
+	return s.indices[start:end:end], s.data[start:end:end]
+}
+
+// Rows returns a new matrix holding the rows at indexes, in that order.
+func (s *CSR) Rows(indexes []int) *CSR {
+	result := &CSR{rows: len(indexes), cols: s.cols, indptr: make([]int, 1, len(indexes)+1)}
+	for _, i := range indexes {
+		columns, values := s.RowNonZeros(i)
+		result.indices = append(result.indices, columns...)
+		result.data = append(result.data, values...)
+		result.indptr = append(result.indptr, len(result.indices))
+	}
+	return result
+}
+
+// T returns the transpose of s as a CSC that shares memory with s.
+func (s *CSR) T() *CSC {
+	return &CSC{rows: s.cols, cols: s.rows, indptr: s.indptr, indices: s.indices, data: s.data}
+}
+
+// ToCSC copies s into a new CSC.
+func (s *CSR) ToCSC() *CSC {
+	indptr, indices, data := transposeCompressed(s.rows, s.cols, s.indptr, s.indices, s.data)
+	return &CSC{rows: s.rows, cols: s.cols, indptr: indptr, indices: indices, data: data}
+}
+
+// ToDense copies s into a new Dense.
+func (s *CSR) ToDense() *Dense {
+	result := NewDense(s.rows, s.cols, nil)
+	for r := 0; r < s.rows; r++ {
+		row := result.Row(r)
+		columns, values := s.RowNonZeros(r)
+		for p, c := range columns {
+			row[c] = values[p]
+		}
+	}
+	return result
+}
+
+// Slices copies s into a new [][]float64.
+func (s *CSR) Slices() [][]float64 {
+	return s.ToDense().Slices()
+}
+
+// Dims returns the number of rows and columns.
+func (s *CSC) Dims() (int, int) {
+	return s.rows, s.cols
+}
+
+// NNZ returns the number of values that are stored.
+func (s *CSC) NNZ() int {
+	return len(s.data)
+}
+
+// At returns the value at row i, column j.
+func (s *CSC) At(i int, j int) float64 {
+	return compressedAt(s.indptr, s.indices, s.data, j, i)
+}
+
+// ColNonZeros returns the rows and values of the non-zero entries of column
+// j, sharing memory with the matrix.
+func (s *CSC) ColNonZeros(j int) ([]int, []float64) {
+	start, end := s.indptr[j], s.indptr[j+1]
+	return s.indices[start:end:end], s.data[start:end:end]
+}
+
+// T returns the transpose of s as a CSR that shares memory with s.
+func (s *CSC) T() *CSR {
+	return &CSR{rows: s.cols, cols: s.rows, indptr: s.indptr, indices: s.indices, data: s.data}
+}
+
+// ToCSR copies s into a new CSR.
+func (s *CSC) ToCSR() *CSR {
+	indptr, indices, data := transposeCompressed(s.cols, s.rows, s.indptr, s.indices, s.data)
+	return &CSR{rows: s.rows, cols: s.cols, indptr: indptr, indices: indices, data: data}
+}
+
+// ToDense copies s into a new Dense.
+func (s *CSC) ToDense() *Dense {
+	result := NewDense(s.rows, s.cols, nil)
+	for c := 0; c < s.cols; c++ {
+		rows, values := s.ColNonZeros(c)
+		for p, r := range rows {
+			result.Set(r, c, values[p])
+		}
+	}
+	return result
+}
+
+// compressedAt finds the value at position j of compressed row (or column) i.
+func compressedAt(indptr []int, indices []int, data []float64, i int, j int) float64 {
+	start, end := indptr[i], indptr[i+1]
+	p := start + sort.SearchInts(indices[start:end], j)
+	if p < end && indices[p] == j {
+		return data[p]
+	}
+	return 0
+}
+
+// transposeCompressed converts n compressed rows of length m into m
+// compressed columns of length n, keeping the indices in order.
+func transposeCompressed(n int, m int, indptr []int, indices []int, data []float64) ([]int, []int, []float64) {
+	counts := make([]int, m+1)
+	for _, j := range indices {
+		counts[j+1]++
+	}
+	for j := 0; j < m; j++ {
+		counts[j+1] += counts[j]
+	}
+	resultIndices := make([]int, len(indices))
+	resultData := make([]float64, len(data))
+	next := append([]int(nil), counts[:m]...)
+	for i := 0; i < n; i++ {
+		for p := indptr[i]; p < indptr[i+1]; p++ {
+			j := indices[p]
+			resultIndices[next[j]] = i
+			resultData[next[j]] = data[p]
+			next[j]++
+		}
+	}
+	return counts, resultIndices, resultData
+}
+
+// AddBiasCSR returns a copy of X with a column of ones added before the first
+// column, the sparse version of AddBias.
+func AddBiasCSR(X *CSR) *CSR {
+	result := &CSR{
+		rows:    X.rows,
+		cols:    X.cols + 1,
+		indptr:  make([]int, X.rows+1),
+		indices: make([]int, 0, X.NNZ()+X.rows),
+		data:    make([]float64, 0, X.NNZ()+X.rows),
+	}
+	for r := 0; r < X.rows; r++ {
+		columns, values := X.RowNonZeros(r)
+		result.indices = append(result.indices, 0)
+		result.data = append(result.data, 1)
+		for p, c := range columns {
+			result.indices = append(result.indices, c+1)
+			result.data = append(result.data, values[p])
+		}
+		result.indptr[r+1] = len(result.indices)
+	}
+	return result
+}
+
+// MulCSR sets d to the product a b of a sparse and a dense matrix.  Only the
+// non-zero values of a are visited.
+func (d *Dense) MulCSR(a *CSR, b *Dense) {
+	if a.cols != b.rows {
+		panic(fmt.Sprintf("ml: can't multiply %d x %d by %d x %d", a.rows, a.cols, b.rows, b.cols))
+	}
+	d.checkShape(a.rows, b.cols)
+	d.Zero()
+	for r := 0; r < a.rows; r++ {
+		dRow := d.Row(r)
+		columns, values := a.RowNonZeros(r)
+		for p, k := range columns {
+			v := values[p]
+			for c, w := range b.Row(k) {
+				dRow[c] += v * w
+			}
+		}
+	}
+}
+
+// MulCSC sets d to the product a b of a sparse and a dense matrix.  Only the
+// non-zero values of a are visited.  d must not share memory with b.
+func (d *Dense) MulCSC(a *CSC, b *Dense) {
+	if a.cols != b.rows {
+		panic(fmt.Sprintf("ml: can't multiply %d x %d by %d x %d", a.rows, a.cols, b.rows, b.cols))
+	}
+	d.checkShape(a.rows, b.cols)
+	d.Zero()
+	for k := 0; k < a.cols; k++ {
+		bRow := b.Row(k)
+		rows, values := a.ColNonZeros(k)
+		for p, r := range rows {
+			v := values[p]
+			dRow := d.Row(r)
+			for c, w := range bRow {
+				dRow[c] += v * w
+			}
+		}
+	}
+}
+
+// PredictCSR runs the linear model w against every row of the sparse matrix
+// X.
+func PredictCSR(X *CSR, w [][]float64) [][]float64 {
+	if X.rows == 0 {
+		return nil
+	}
+	result := NewDense(X.rows, len(w[0]), nil)
+	result.MulCSR(X, DenseOf(w))
+	return result.Slices()
+}
@@ -0,0 +1,126 @@
+package ml
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// sparseMatrix returns a rows x cols matrix where about one value in five is
+// not zero, with an empty row and an empty column.
+func sparseMatrix(rng *rand.Rand, rows int, cols int) [][]float64 {
+	result := Zeros(rows, cols)
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			if r != 1 && c != 2 && rng.Intn(5) == 0 {
+				result[r][c] = rng.NormFloat64()
+			}
+		}
+	}
+	return result
+}
+
+func TestSparseRoundTrip(t *testing.T) {
+	m := sparseMatrix(rand.New(rand.NewSource(1)), 7, 5)
+	csr := CSROf(m)
+	nnz := 0
+	for r := range m {
+		for _, v := range m[r] {
+			if v != 0 {
+				nnz++
+			}
+		}
+	}
+	if csr.NNZ() != nnz {
+		t.Fatalf("NNZ() = %d, want %d", csr.NNZ(), nnz)
+	}
+	if rows, cols := csr.Dims(); rows != 7 || cols != 5 {
+		t.Fatalf("Dims() = %d, %d, want 7, 5", rows, cols)
+	}
+
+	csc := csr.ToCSC()
+	assertClose(t, "CSR to dense", csr.ToDense().Slices(), m, 0)
+	assertClose(t, "CSR slices", csr.Slices(), m, 0)
+	assertClose(t, "CSC to dense", csc.ToDense().Slices(), m, 0)
+	assertClose(t, "CSC to CSR", csc.ToCSR().Slices(), m, 0)
+	for r := range m {
+		for c := range m[r] {
+			if csr.At(r, c) != m[r][c] || csc.At(r, c) != m[r][c] {
+				t.Fatalf("At(%d, %d) = %v and %v, want %v", r, c, csr.At(r, c), csc.At(r, c), m[r][c])
+			}
+		}
+	}
+
+	//the transpose swaps the layout without moving any values
+	assertClose(t, "CSR T", csr.T().ToDense().Slices(), DenseOf(m).T().Slices(), 0)
+	assertClose(t, "CSC T", csc.T().ToDense().Slices(), DenseOf(m).T().Slices(), 0)
+
+	assertClose(t, "Rows", csr.Rows([]int{6, 0, 1}).Slices(), Rows(m, []int{6, 0, 1}), 0)
+	assertClose(t, "AddBiasCSR", AddBiasCSR(csr).Slices(), AddBias(m), 0)
+}
+
+func TestNewCSR(t *testing.T) {
+	//[[1 0 2] [0 0 0] [0 3 0]]
+	csr := NewCSR(3, 3, []int{0, 2, 2, 3}, []int{0, 2, 1}, []float64{1, 2, 3})
+	assertClose(t, "NewCSR", csr.Slices(), [][]float64{{1, 0, 2}, {0, 0, 0}, {0, 3, 0}}, 0)
+	csc := NewCSC(3, 3, []int{0, 1, 2, 3}, []int{0, 2, 0}, []float64{1, 3, 2})
+	assertClose(t, "NewCSC", csc.ToDense().Slices(), [][]float64{{1, 0, 2}, {0, 0, 0}, {0, 3, 0}}, 0)
+
+	assertPanics(t, "don't describe 3 rows", func() { NewCSR(3, 3, []int{0, 2, 3}, []int{0, 2, 1}, []float64{1, 2, 3}) })
+	assertPanics(t, "out of order", func() { NewCSR(1, 3, []int{0, 2}, []int{2, 0}, []float64{1, 2}) })
+	assertPanics(t, "out of range 3", func() { NewCSR(1, 3, []int{0, 1}, []int{3}, []float64{1}) })
+}
+
+func TestSparseMul(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	a := sparseMatrix(rng, 9, 6)
+	b := randomMatrix(rng, 6, 4)
+	want := naiveMul(a, b)
+
+	d := NewDense(9, 4, nil)
+	d.MulCSR(CSROf(a), DenseOf(b))
+	assertClose(t, "MulCSR", d.Slices(), want, 1e-12)
+
+	d = NewDense(9, 4, nil)
+	d.MulCSC(CSROf(a).ToCSC(), DenseOf(b))
+	assertClose(t, "MulCSC", d.Slices(), want, 1e-12)
+
+	assertClose(t, "PredictCSR", PredictCSR(CSROf(a), b), want, 1e-12)
+
+	assertPanics(t, "can't multiply 9 x 6 by 4 x 4", func() { NewDense(9, 4, nil).MulCSR(CSROf(a), NewDense(4, 4, nil)) })
+	assertPanics(t, "can't multiply 9 x 6 by 4 x 4", func() { NewDense(9, 4, nil).MulCSC(CSROf(a).ToCSC(), NewDense(4, 4, nil)) })
+	assertPanics(t, "destination is 9 x 3, expected 9 x 4", func() { NewDense(9, 3, nil).MulCSR(CSROf(a), DenseOf(b)) })
+}
+
+func TestTrainCSRMatchesTrainDense(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	X := AddBias(sparseMatrix(rng, 40, 8))
+	w := randomMatrix(rng, 9, 2)
+	T := Predict(X, w)
+
+	tests := []struct {
+		name string
+		sgd  SGD
+	}{
+		{"sample by sample", SGD{LearningRate: 0.05, Epochs: 5, Seed: 1}},
+		{"mini-batch", SGD{LearningRate: 0.05, Epochs: 5, BatchSize: 8, Seed: 1}},
+		{"l2", SGD{LearningRate: 0.05, Epochs: 5, L2: 0.1, Seed: 1}},
+		{"adam", SGD{LearningRate: 0.01, Epochs: 5, Optimizer: &Adam{}, Seed: 1}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dense := NewDense(9, 2, nil)
+			if err := test.sgd.TrainDense(DenseOf(X), DenseOf(T), dense); err != nil {
+				t.Fatal(err)
+			}
+			//the optimizer keeps state, so each run gets its own
+			if test.sgd.Optimizer != nil {
+				test.sgd.Optimizer = &Adam{}
+			}
+			sparse := NewDense(9, 2, nil)
+			if err := test.sgd.TrainCSR(CSROf(X), DenseOf(T), sparse); err != nil {
+				t.Fatal(err)
+			}
+			assertClose(t, "w", sparse.Slices(), dense.Slices(), 1e-12)
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Splitter divides rows into training, validation and test sets.  The same
+// Seed always gives the same split, so a logged run can be reproduced.
+type Splitter struct {
+	//Train is the fraction of the rows used for training.
+	Train float64
+	//Validation is the fraction of the rows used for validation, the rows that
+	//are left over are the test set.
+	Validation float64
+
+	Seed int64
+	//Rand, when set, is used instead of a source created from Seed.
+	Rand *rand.Rand
+}
+
+// Split holds the row indexes of each set, in increasing order.
+type Split struct {
+	Train      []int
+	Validation []int
+	Test       []int
+}
+
+// Split shuffles the indexes 0 through n-1 and divides them up.
+func (s Splitter) Split(n int) (Split, error) {
+	if err := s.check(); err != nil {
+		return Split{}, err
+	}
+
+	trainCount := int(math.Round(float64(n) * s.Train))
+	validationCount := int(math.Round(float64(n) * s.Validation))
+	if trainCount+validationCount > n {
+		validationCount = n - trainCount
+	}
+
+	perm := s.rand().Perm(n)
+	result := Split{
+		Train:      sortedCopy(perm[:trainCount]),
+		Validation: sortedCopy(perm[trainCount : trainCount+validationCount]),
+		Test:       sortedCopy(perm[trainCount+validationCount:]),
+	}
+	return result, nil
+}
+
+// Stratified splits the rows so that every class of y is divided with the
+// same ratios, which keeps rare classes in every set.  When bins is more than
+// zero y is continuous and is first divided into that many quantile bins.
+// Rows with a missing label, NaN, are split as a class of their own.
+// Classes with at least one row for each set get at least one row in each
+// set that has a ratio above zero.
+func (s Splitter) Stratified(y []float64, bins int) (Split, error) {
+	if err := s.check(); err != nil {
+		return Split{}, err
+	}
+
+	testRatio := 1 - s.Train - s.Validation
+	rng := s.rand()
+	var result Split
+	for _, rows := range strata(y, bins) {
+		n := len(rows)
+		validationCount := int(math.Round(float64(n) * s.Validation))
+		testCount := int(math.Round(float64(n) * testRatio))
+		if testRatio > 1e-9 && testCount == 0 && n >= 2 {
+			testCount = 1
+		}
+		if s.Validation > 0 && validationCount == 0 && n >= 3 {
+			validationCount = 1
+		}
+		for validationCount+testCount >= n && validationCount+testCount > 0 {
+			//always leave at least one row of the class for training
+			if validationCount >= testCount {
+				validationCount--
+			} else {
+				testCount--
+			}
+		}
+		trainCount := n - validationCount - testCount
+
+		perm := rng.Perm(n)
+		for i, p := range perm {
+			switch {
+			case i < trainCount:
+				result.Train = append(result.Train, rows[p])
+			case i < trainCount+validationCount:
+				result.Validation = append(result.Validation, rows[p])
+			default:
+				result.Test = append(result.Test, rows[p])
+			}
+		}
+	}
+
+	result.Train = sortedCopy(result.Train)
+	result.Validation = sortedCopy(result.Validation)
+	result.Test = sortedCopy(result.Test)
+	return result, nil
+}
+
+// Grouped splits the rows so that rows with the same group key always end up
+// in the same set.  Whole groups are shuffled and handed out until each set
+// has at least its share of the rows, so the sizes of the sets are only close
+// to the ratios.
+func (s Splitter) Grouped(groups []string) (Split, error) {
+	if err := s.check(); err != nil {
+		return Split{}, err
+	}
+
+	members := make(map[string][]int)
+	var keys []string
+	for i, g := range groups {
+		if _, ok := members[g]; !ok {
+			keys = append(keys, g)
+		}
+		members[g] = append(members[g], i)
+	}
+
+	n := len(groups)
+	trainCount := int(math.Round(float64(n) * s.Train))
+	validationCount := int(math.Round(float64(n) * s.Validation))
+
+	var result Split
+	for _, p := range s.rand().Perm(len(keys)) {
+		rows := members[keys[p]]
+		switch {
+		case len(result.Train) < trainCount:
+			result.Train = append(result.Train, rows...)
+		case len(result.Validation) < validationCount:
+			result.Validation = append(result.Validation, rows...)
+		default:
+			result.Test = append(result.Test, rows...)
+		}
+	}
+
+	result.Train = sortedCopy(result.Train)
+	result.Validation = sortedCopy(result.Validation)
+	result.Test = sortedCopy(result.Test)
+	return result, nil
+}
+
+// strata returns the rows of every class of y, in order of the class.  When
+// bins is more than zero y is first divided into that many quantile bins.
+// The rows with a missing label, NaN, make up the last class.
+func strata(y []float64, bins int) [][]int {
+	labels := y
+	if bins > 0 {
+		labels = quantileBins(y, bins)
+	}
+	//NaN is never equal to itself so it can't be a key of the map
+	classes := make(map[float64][]int)
+	var keys []float64
+	var missing []int
+	for i, v := range labels {
+		if IsMissing(v) {
+			missing = append(missing, i)
+			continue
+		}
+		if _, ok := classes[v]; !ok {
+			keys = append(keys, v)
+		}
+		classes[v] = append(classes[v], i)
+	}
+	sort.Float64s(keys)
+
+	var result [][]int
+	for _, k := range keys {
+		result = append(result, classes[k])
+	}
+	if len(missing) > 0 {
+		result = append(result, missing)
+	}
+	return result
+}
+
+// quantileBins labels every value with the number of the quantile bin it falls
+// in, from 0 through bins-1.  Missing values stay missing.
+func quantileBins(y []float64, bins int) []float64 {
+	var sorted []float64
+	for _, v := range y {
+		if !IsMissing(v) {
+			sorted = append(sorted, v)
+		}
+	}
+	sort.Float64s(sorted)
+	var cuts []float64
+	for b := 1; b < bins && len(sorted) > 0; b++ {
+		cuts = append(cuts, sorted[b*len(sorted)/bins])
+	}
+	result := make([]float64, len(y))
+	for i, v := range y {
+		if IsMissing(v) {
+			result[i] = v
+			continue
+		}
+		//number of cut points at or below the value
+		result[i] = float64(sort.Search(len(cuts), func(c int) bool { return cuts[c] > v }))
+	}
+	return result
+}
+
+// check makes sure the ratios can be used.
+func (s Splitter) check() error {
+	if s.Train <= 0 || s.Validation < 0 || s.Train+s.Validation > 1 {
+		return fmt.Errorf("splitter: invalid ratios train=%v validation=%v", s.Train, s.Validation)
+	}
+	return nil
+}
+
+func (s Splitter) rand() *rand.Rand {
+	if s.Rand != nil {
+		return s.Rand
+	}
+	return rand.New(rand.NewSource(s.Seed))
+}
+
+// sortedCopy returns the indexes in increasing order.
+func sortedCopy(indexes []int) []int {
+	result := append([]int{}, indexes...)
+	sort.Ints(result)
+	return result
+}
+
+// Rows returns the rows of X at the given indexes, in that order.
+func Rows(X [][]float64, indexes []int) [][]float64 {
+	result := make([][]float64, len(indexes))
+	for i, r := range indexes {
+		result[i] = X[r]
+	}
+	return result
+}
@@ -0,0 +1,186 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestSplitterSplit(t *testing.T) {
+	tests := []struct {
+		name                    string
+		splitter                Splitter
+		n                       int
+		train, validation, test int
+	}{
+		{"train and test", Splitter{Train: 0.8, Seed: 1}, 10, 8, 0, 2},
+		{"train, validation and test", Splitter{Train: 0.6, Validation: 0.2, Seed: 1}, 1599, 959, 320, 320},
+		{"everything for training", Splitter{Train: 1}, 5, 5, 0, 0},
+		{"rounding leaves no test rows", Splitter{Train: 0.5, Validation: 0.5}, 3, 2, 1, 0},
+		{"no rows", Splitter{Train: 0.8}, 0, 0, 0, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			split, err := test.splitter.Split(test.n)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(split.Train) != test.train || len(split.Validation) != test.validation || len(split.Test) != test.test {
+				t.Fatalf("got %d/%d/%d rows, want %d/%d/%d", len(split.Train), len(split.Validation), len(split.Test),
+					test.train, test.validation, test.test)
+			}
+			//every row is in exactly one set, and each set is sorted
+			seen := make([]bool, test.n)
+			for _, set := range [][]int{split.Train, split.Validation, split.Test} {
+				for i, r := range set {
+					if seen[r] {
+						t.Fatalf("row %d is in more than one set", r)
+					}
+					seen[r] = true
+					if i > 0 && set[i-1] >= r {
+						t.Fatalf("set %v is not in increasing order", set)
+					}
+				}
+			}
+			for r, ok := range seen {
+				if !ok {
+					t.Fatalf("row %d is in no set", r)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitterSeed(t *testing.T) {
+	a, err := Splitter{Train: 0.5, Seed: 7}.Split(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Splitter{Train: 0.5, Seed: 7}.Split(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Fatal("the same seed gave different splits")
+	}
+	c, err := Splitter{Train: 0.5, Seed: 8}.Split(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reflect.DeepEqual(a, c) {
+		t.Fatal("different seeds gave the same split")
+	}
+}
+
+func TestSplitterErrors(t *testing.T) {
+	for _, s := range []Splitter{{}, {Train: -0.1}, {Train: 0.8, Validation: 0.3}, {Train: 0.5, Validation: -0.1}} {
+		_, err := s.Split(10)
+		assertErrorContains(t, err, "invalid ratios")
+	}
+}
+
+func TestRows(t *testing.T) {
+	X := [][]float64{{0}, {1}, {2}, {3}}
+	assertClose(t, "Rows", Rows(X, []int{3, 1, 1}), [][]float64{{3}, {1}, {1}}, 0)
+}
+
+// countMissing returns how many of the rows have a missing label in y.
+func countMissing(y []float64, rows []int) int {
+	result := 0
+	for _, r := range rows {
+		if IsMissing(y[r]) {
+			result++
+		}
+	}
+	return result
+}
+
+func TestSplitterStratifiedMissing(t *testing.T) {
+	nan := math.NaN()
+	y := []float64{nan, 1, 2, nan, 1, 2, nan, 1, 2, nan, 1, 2}
+	for _, bins := range []int{0, 2} {
+		split, err := Splitter{Train: 0.5, Seed: 1}.Stratified(y, bins)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(split.Train)+len(split.Test) != len(y) {
+			t.Fatalf("bins %d: split %v lost rows", bins, split)
+		}
+		if countMissing(y, split.Train) != 2 || countMissing(y, split.Test) != 2 {
+			t.Fatalf("bins %d: split %v doesn't divide the missing labels evenly", bins, split)
+		}
+	}
+}
+
+func TestSplitterGrouped(t *testing.T) {
+	//20 groups of different sizes, the rows of a group are spread through the data
+	var groups []string
+	for r := 0; r < 100; r++ {
+		groups = append(groups, fmt.Sprintf("group %d", (r*r)%20))
+	}
+	for seed := int64(0); seed < 10; seed++ {
+		split, err := Splitter{Train: 0.6, Validation: 0.2, Seed: seed}.Grouped(groups)
+		if err != nil {
+			t.Fatal(err)
+		}
+		set := make(map[string]string)
+		rows := 0
+		for name, indexes := range map[string][]int{"train": split.Train, "validation": split.Validation, "test": split.Test} {
+			rows += len(indexes)
+			for _, r := range indexes {
+				if other, ok := set[groups[r]]; ok && other != name {
+					t.Fatalf("seed %d: %s is in both %s and %s", seed, groups[r], other, name)
+				}
+				set[groups[r]] = name
+			}
+		}
+		if rows != len(groups) {
+			t.Fatalf("seed %d: %d rows in the sets, want %d", seed, rows, len(groups))
+		}
+	}
+}
+
+func TestSplitterStratifiedRareClasses(t *testing.T) {
+	//one common class and classes with 1 to 4 rows
+	var y []float64
+	for r := 0; r < 90; r++ {
+		y = append(y, 5)
+	}
+	for class := 1; class <= 4; class++ {
+		for r := 0; r < class; r++ {
+			y = append(y, float64(class))
+		}
+	}
+	counts := make(map[float64]int)
+	for _, v := range y {
+		counts[v]++
+	}
+	for seed := int64(0); seed < 10; seed++ {
+		split, err := Splitter{Train: 0.8, Validation: 0.1, Seed: seed}.Stratified(y, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for name, indexes := range map[string][]int{"train": split.Train, "validation": split.Validation, "test": split.Test} {
+			found := make(map[float64]bool)
+			for _, r := range indexes {
+				found[y[r]] = true
+			}
+			for class, n := range counts {
+				if n >= 3 && !found[class] {
+					t.Fatalf("seed %d: class %v with %d rows is missing from %s", seed, class, n, name)
+				}
+			}
+		}
+		//a class too small for every set still keeps a row for training
+		found := false
+		for _, r := range split.Train {
+			if y[r] == 1 {
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("seed %d: the class with 1 row is not in train", seed)
+		}
+	}
+}
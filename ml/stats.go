@@ -0,0 +1,113 @@
+package ml
+
+import (
+	"math"
+	"sort"
+)
+
+// MeanByColumn returns the mean of every column of slice.
+func MeanByColumn(slice [][]float64) []float64 {
+	rowCount := len(slice)
+	colLengh := len(slice[0])
+	sums := make([]float64, colLengh)
+	for r := 0; r < rowCount; r++ {
+		for c := 0; c < colLengh; c++ {
+			sums[c] += slice[r][c]
+		}
+	}
+	for c := 0; c < colLengh; c++ {
+		sums[c] = sums[c] / float64(rowCount)
+	}
+	return sums
+}
+
+// StdDevByColumn returns the population standard deviation of every column of
+// slice.
+func StdDevByColumn(slice [][]float64) []float64 {
+	rowCount := len(slice)
+	colLengh := len(slice[0])
+	result := make([]float64, colLengh)
+	//find mean by column
+	means := MeanByColumn(slice)
+	for r := 0; r < rowCount; r++ {
+		for c := 0; c < colLengh; c++ {
+			result[c] += math.Pow(slice[r][c]-means[c], 2.0)
+		}
+	}
+	for c := 0; c < colLengh; c++ {
+		result[c] = math.Sqrt(result[c] / float64(rowCount))
+	}
+	return result
+}
+
+// median returns the middle value of values, or the mean of the two middle
+// values when there is an even number of them.  values is not modified.
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2.0
+}
+
+// mostFrequent returns the value that occurs most often, the smallest one when
+// there is a tie.
+func mostFrequent(values []float64) float64 {
+	counts := make(map[float64]int)
+	for _, v := range values {
+		counts[v]++
+	}
+	best := math.NaN()
+	bestCount := 0
+	for v, count := range counts {
+		if count > bestCount || (count == bestCount && v < best) {
+			best = v
+			bestCount = count
+		}
+	}
+	return best
+}
+
+// presentColumn returns the values of column c of X that are not missing.
+func presentColumn(X [][]float64, c int) []float64 {
+	var result []float64
+	for r := range X {
+		if !IsMissing(X[r][c]) {
+			result = append(result, X[r][c])
+		}
+	}
+	return result
+}
+
+// quantile returns the q quantile (0 through 1) of sorted values, using linear
+// interpolation between the closest values.
+func quantile(sorted []float64, q float64) float64 {
+	position := q * float64(len(sorted)-1)
+	below := int(math.Floor(position))
+	if below >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	fraction := position - float64(below)
+	return sorted[below] + fraction*(sorted[below+1]-sorted[below])
+}
+
+// variance returns the population variance of values.
+func variance(values []float64) float64 {
+	m := mean(values)
+	result := 0.0
+	for _, v := range values {
+		result += (v - m) * (v - m)
+	}
+	return result / float64(len(values))
+}
+
+// mean returns the average of the values.
+func mean(values []float64) float64 {
+	result := 0.0
+	for _, v := range values {
+		result += v
+	}
+	return result / float64(len(values))
+}